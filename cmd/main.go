@@ -2,180 +2,152 @@ package main
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"os"
 	"os/signal"
-	"time"
-)
+	"syscall"
 
-type Response struct {
-	Code    int     `json:"code"`
-	Message string  `json:"message"`
-	Data    []Event `json:"data"`
-}
+	"earthquake-alert/internal/alert"
+)
 
-type Event struct {
-	EventId   int     `json:"eventId"`
-	Updates   int     `json:"updates"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	Depth     float64 `json:"depth"`
-	Epicenter string  `json:"epicenter"`
-	StartAt   int64   `json:"startAt"`
-	UpdateAt  int64   `json:"updateAt"`
-	Magnitude float64 `json:"magnitude"`
-	InsideNet int     `json:"insideNet"`
-	Sations   int     `json:"sations"`
+// parseConfig parses the command line, loading the config file first when -config is given
+// so that flags on the command line take precedence over it. It returns the flag set of the usage. A leading
+// history command is the same as -history, the flag parsing stopping at the first argument.
+func parseConfig(args []string) (*alert.Config, *flag.FlagSet, error) {
+	history := len(args) > 0 && args[0] == "history"
+	if history {
+		args = args[1:]
+	}
+	cfg, fs, err := parseFlags(args)
+	if err == nil && history {
+		cfg.History = true
+	}
+	return cfg, fs, err
 }
 
-func query[T any](ctx context.Context, lastTs int64, update int) (*T, error) {
-	url := fmt.Sprintf("https://mobile-new.chinaeew.cn/v1/earlywarnings?start_at=%d&updates=%d", lastTs, update)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
+func parseFlags(args []string) (*alert.Config, *flag.FlagSet, error) {
+	cfg := alert.DefaultConfig()
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	bindFlags(fs, cfg)
+	_ = fs.Parse(args)
+	if fs.Arg(0) == "version" {
+		cfg.ShowVersion = true
 	}
-	response, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	if cfg.ShowVersion || cfg.ConfigFile == "" {
+		return cfg, fs, nil
 	}
-	defer func() {
-		_ = response.Body.Close()
-	}()
 
-	data, err := io.ReadAll(response.Body)
+	cfg, err := alert.LoadConfig(cfg.ConfigFile)
 	if err != nil {
-		return nil, err
-	}
-	var resp T
-	if err = json.Unmarshal(data, &resp); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &resp, nil
+	fs = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	bindFlags(fs, cfg)
+	_ = fs.Parse(args)
+	return cfg, fs, nil
 }
 
-func loop(ctx context.Context, notification chan<- Event) {
-	ticker := time.NewTicker(*duration)
-	defer func() {
-		ticker.Stop()
-	}()
-
-	var (
-		lastTs      int64 = 0
-		lastEventID       = 0
-		update            = 0
-	)
-
-	for {
-		select {
-		case <-ticker.C:
-			resp, err := query[Response](ctx, lastTs, update)
-			if err != nil {
-				slog.Error("query data", "err", err)
-			} else {
-				if resp != nil && len(resp.Data) > 0 {
-					if resp.Data[0].EventId == lastEventID {
-						continue
-					}
-					slog.Info("found the events", "num", len(resp.Data), "events", resp.Data)
-					lastTs = resp.Data[0].StartAt
-					update = resp.Data[0].Updates
-					lastEventID = resp.Data[0].EventId
-					tt := time.UnixMilli(lastTs)
-					if time.Since(tt) <= 30*time.Minute {
-						notification <- resp.Data[0]
-					} else {
-						slog.Info("the latest event is out of date", "startAt", tt.String(), "event", resp.Data[0])
-					}
-				}
-			}
-		case <-ctx.Done():
-			slog.Info("loop exiting")
-			return
-		}
-		ticker.Reset(*duration)
+// validate checks cfg, printing the error and the usage of fs then exiting with 2 when it is invalid.
+func validate(fs *flag.FlagSet, cfg *alert.Config) {
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(fs.Output(), "invalid configuration: %v\n", err)
+		fs.Usage()
+		os.Exit(2)
 	}
 }
 
-func notification(ctx context.Context, ch <-chan Event) {
-	fn := func(event Event) error {
-		tz, err := time.LoadLocation("Asia/Shanghai")
-		if err != nil {
-			return err
-		}
-		url := fmt.Sprintf("https://api.day.app/%s/%s/%s", *key,
-			fmt.Sprintf("%s 有%.1f级地震发生了", time.UnixMilli(event.StartAt).In(tz).Format(time.DateTime), event.Magnitude),
-			fmt.Sprintf("地点:%s,东经:%.1f°,北纬:%.1f°,地震深度:%.1f公里", event.Epicenter, event.Longitude, event.Latitude, event.Depth))
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return err
-		}
-		response, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer func() {
-			_ = response.Body.Close()
-		}()
-
-		data, err := io.ReadAll(response.Body)
-		if err != nil {
-			return err
-		}
-		slog.Info("notification successfully", "result", string(data))
-		return nil
-
+func main() {
+	cfg, fs, err := parseConfig(os.Args[1:])
+	if err != nil {
+		slog.Error("load config", "err", err)
+		os.Exit(2)
 	}
-	defer func() {
-		slog.Info("notification exiting...")
-	}()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case event := <-ch:
-			if err := fn(event); err != nil {
-				slog.Error("send notification failed", "err", err)
-			}
+	if cfg.ShowVersion {
+		fmt.Println(alert.VersionInfo())
+		return
+	}
+	validate(fs, cfg)
+	logger, err := alert.NewLogger(os.Stderr, cfg.LogFormat, cfg.LogLevel)
+	if err != nil {
+		slog.Error("create logger", "err", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// restore the default behavior so that a second signal terminates immediately
+	context.AfterFunc(ctx, func() {
+		stop()
+		slog.Info("exiting...")
+	})
+	if cfg.History {
+		if err = alert.History(ctx, *cfg, os.Stdout); err != nil {
+			stop()
+			slog.Error("history", "err", err)
+			os.Exit(1)
 		}
+		return
 	}
-}
-
-var (
-	key      = flag.String("key", "", "the key of bar app")
-	duration = flag.Duration("duration", 3*time.Second, "the interval of query data")
-)
-
-var client = http.Client{
-	Transport: &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
-	},
-	Timeout: 10 * time.Second,
-}
+	cfg.Reload = reloadOnHangup(ctx, os.Args[1:])
+	cfg.Dump = dumpOnUser1(ctx)
 
-func main() {
-	flag.Parse()
-	if *key == "" {
-		panic("key should have a value")
+	if err = alert.Run(ctx, *cfg); err != nil {
+		stop()
+		slog.Error("run", "err", err)
+		os.Exit(1)
 	}
+}
 
-	ctx, cancelFunc := context.WithCancel(context.TODO())
-	ch := make(chan Event)
-
-	go notification(ctx, ch)
-	go loop(ctx, ch)
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-	cancelFunc()
+// reloadOnHangup parses the command line and the config file again on every SIGHUP until ctx is done,
+// and sends the configurations to be swapped in by alert.Run.
+func reloadOnHangup(ctx context.Context, args []string) <-chan *alert.Config {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	reloads := make(chan *alert.Config)
+	go func() {
+		defer signal.Stop(hangup)
+		for {
+			select {
+			case <-hangup:
+			case <-ctx.Done():
+				return
+			}
+			cfg, _, err := parseConfig(args)
+			if err != nil {
+				slog.Error("reload config", "err", err)
+				continue
+			}
+			select {
+			case reloads <- cfg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return reloads
+}
 
-	slog.Info("exiting...")
+// dumpOnUser1 requests a snapshot of the state of alert.Run on every SIGUSR1 until ctx is done.
+func dumpOnUser1(ctx context.Context) <-chan struct{} {
+	user1 := make(chan os.Signal, 1)
+	signal.Notify(user1, syscall.SIGUSR1)
+	dumps := make(chan struct{}, 1)
+	go func() {
+		defer signal.Stop(user1)
+		for {
+			select {
+			case <-user1:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case dumps <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return dumps
 }
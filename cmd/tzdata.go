@@ -0,0 +1,7 @@
+//go:build !notzdata
+
+package main
+
+// The timezone database is embedded so that -timezone works in images without zoneinfo,
+// build with -tags notzdata to leave it out.
+import _ "time/tzdata"
@@ -0,0 +1,58 @@
+package alert
+
+import "time"
+
+// AftershockConfig annotates the events within RadiusKm and Window after a larger event as its possible aftershocks.
+type AftershockConfig struct {
+	// RadiusKm is the distance from the mainshock, 0 disables the annotation.
+	RadiusKm float64       `yaml:"radius_km"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// Mainshock is the larger event an event possibly is an aftershock of.
+type Mainshock struct {
+	EventId   int     `json:"eventId"`
+	Epicenter string  `json:"epicenter"`
+	StartAt   int64   `json:"startAt"`
+	Magnitude float64 `json:"magnitude"`
+}
+
+// aftershockCache keeps the events of the last window to find the mainshocks of the next ones.
+type aftershockCache struct {
+	events map[int]Event
+}
+
+func newAftershockCache() *aftershockCache {
+	return &aftershockCache{events: make(map[int]Event)}
+}
+
+// mainshock records the event and returns the largest earlier event within the radius and the window larger
+// than it, nil when there is none. An update of an event replaces it.
+func (c *aftershockCache) mainshock(event Event, cfg AftershockConfig) *Mainshock {
+	if cfg.RadiusKm <= 0 {
+		return nil
+	}
+	since := event.StartAt - cfg.Window.Milliseconds()
+	var (
+		main  Event
+		found bool
+	)
+	for id, e := range c.events {
+		if e.StartAt < since {
+			delete(c.events, id)
+			continue
+		}
+		if id == event.EventId || e.StartAt > event.StartAt || e.Magnitude <= event.Magnitude ||
+			distanceKm(e.Latitude, e.Longitude, event.Latitude, event.Longitude) > cfg.RadiusKm {
+			continue
+		}
+		if !found || e.Magnitude > main.Magnitude {
+			main, found = e, true
+		}
+	}
+	c.events[event.EventId] = event
+	if !found {
+		return nil
+	}
+	return &Mainshock{EventId: main.EventId, Epicenter: main.Epicenter, StartAt: main.StartAt, Magnitude: main.Magnitude}
+}
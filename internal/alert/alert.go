@@ -0,0 +1,492 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Event is an earthquake normalized from any source, with the JSON shape of the China EEW feed.
+type Event struct {
+	EventId   int     `json:"eventId"`
+	Updates   int     `json:"updates"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Depth     float64 `json:"depth"`
+	Epicenter string  `json:"epicenter"`
+	StartAt   int64   `json:"startAt"`
+	UpdateAt  int64   `json:"updateAt"`
+	Magnitude float64 `json:"magnitude"`
+	InsideNet int     `json:"insideNet"`
+	Sations   int     `json:"sations"`
+
+	// Source is the name of the source that reported the event when several are configured, see FailoverSource.
+	Source string `json:"source,omitempty"`
+	// Revision marks the notification of an update whose magnitude changed since the event was notified.
+	Revision bool `json:"revision,omitempty"`
+	// Tsunami marks a strong offshore event, alerted with the highest priority, see tsunamiRisk.
+	Tsunami bool `json:"tsunami,omitempty"`
+	// Swarm is the number of events of the swarm the event completes, alerted besides the event itself, see swarmDetector.
+	Swarm int `json:"swarm,omitempty"`
+	// Mainshock is the larger recent event nearby the event is possibly an aftershock of, see aftershockCache.
+	Mainshock *Mainshock `json:"mainshock,omitempty"`
+
+	// Local is the estimate at the configured location, nil when no location is configured.
+	Local *Local `json:"local,omitempty"`
+}
+
+// notice is an event queued for the notifiers.
+type notice struct {
+	event Event
+	// span is the span of the poll that found the event, the parent of the spans of its notification.
+	span trace.SpanContext
+}
+
+// Local describes how an event reaches the configured location.
+type Local struct {
+	// Distance is the epicentral distance in kilometers.
+	Distance float64 `json:"distance"`
+	// SWaveArrival is the estimated unix milli time the S-wave arrives.
+	SWaveArrival int64 `json:"sWaveArrival"`
+	// Intensity is the estimated seismic intensity (烈度).
+	Intensity float64 `json:"intensity"`
+}
+
+// loopControl carries the requests that loop handles between the polls.
+type loopControl struct {
+	// reloads replace the configuration, keeping the progress and the caches of the events.
+	reloads <-chan *Config
+	// dumps log a snapshot of the state of loop, along with the notifiers.
+	dumps     <-chan struct{}
+	notifiers fmt.Stringer
+}
+
+// loopState is the progress of loop and its caches of the events, kept by Run across the restarts of loop
+// after a panic, so that the restarted loop neither polls from the start nor notifies the events again.
+type loopState struct {
+	// cfg is the configuration in effect, the last reloaded one.
+	cfg         *Config
+	lastTs      int64
+	lastEventID int
+	update      int
+	seen        *seenCache
+	revisions   *revisionCache
+	quakes      *quakeCache
+	swarms      *swarmDetector
+	aftershocks *aftershockCache
+}
+
+// newLoopState starts from the position saved in the state file of cfg, if any, unless source replays.
+func newLoopState(cfg *Config, source Source) *loopState {
+	st := &loopState{
+		cfg:         cfg,
+		seen:        newSeenCache(seenTTL),
+		revisions:   newRevisionCache(time.Hour),
+		quakes:      newQuakeCache(time.Hour),
+		swarms:      newSwarmDetector(),
+		aftershocks: newAftershockCache(),
+	}
+	if _, replaying := source.(*ReplaySource); cfg.StateFile == "" || replaying {
+		return st
+	}
+	saved, err := loadState(cfg.StateFile)
+	if err != nil {
+		slog.Error("load state", "path", cfg.StateFile, "err", err)
+		return st
+	}
+	st.lastTs, st.lastEventID, st.update = saved.LastTs, saved.LastEventID, saved.Updates
+	st.seen.add(Event{EventId: saved.LastEventID, Updates: saved.Updates}, time.Now())
+	return st
+}
+
+// loop polls source and sends the new events passing the filter to notification, every new event is
+// saved to store and published to hub. With st.cfg.Once it polls a single time and returns the error of the poll.
+func loop(ctx context.Context, st *loopState, source Source, store Store, hub *broadcaster, ctl loopControl, notification chan<- notice) error {
+	var (
+		cfg  = st.cfg
+		pace = newPacer(cfg)
+		// replaying shifts the timestamps of recorded events, the resume position is left to the live runs
+		_, replaying = source.(*ReplaySource)
+	)
+	ticker := time.NewTicker(cfg.Interval)
+	defer func() {
+		ticker.Stop()
+	}()
+
+	// poll queries source once and sends the new events to notification, it fails with io.EOF once source
+	// is exhausted and with the error of ctx when ctx is done while waiting for the notifiers.
+	poll := func() (found bool, err error) {
+		pollCtx, span := tracer.Start(ctx, "poll")
+		defer func() {
+			if err != nil && !errors.Is(err, io.EOF) {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+		start := time.Now()
+		data, err := source.Poll(pollCtx, st.lastTs)
+		elapsed := time.Since(start)
+		queryDuration.Observe(elapsed.Seconds())
+		span.SetAttributes(attribute.Int64("query.duration_ms", elapsed.Milliseconds()))
+		if errors.Is(err, io.EOF) {
+			return false, err
+		}
+		if errors.Is(err, errCircuitOpen) {
+			// logged once by the breaker
+			return false, nil
+		}
+		if err != nil {
+			queryErrorsTotal.Inc()
+			slog.Error("query data", "err", err)
+			return false, err
+		}
+		lastQuerySuccess.Store(time.Now().UnixNano())
+		newest := st.lastTs
+		for _, event := range data {
+			newest = max(newest, event.StartAt)
+		}
+		if newest > 0 {
+			newestEventAge.Set(serverNow().Sub(time.UnixMilli(newest)).Seconds())
+		}
+		now := time.Now()
+		late := publishesLate(source)
+		var events []Event
+		for _, event := range data {
+			// the events of a late source are only dropped once older than what the seen cache remembers
+			recent := late && serverNow().Sub(time.UnixMilli(event.StartAt)) < seenTTL
+			if (event.StartAt >= st.lastTs || recent) && !st.seen.seen(event, now) {
+				events = append(events, event)
+			}
+		}
+		span.SetAttributes(attribute.Int("events", len(events)))
+		if len(events) == 0 {
+			return false, nil
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].StartAt < events[j].StartAt
+		})
+		slog.Info("found the events", "num", len(events), "events", events)
+		// enqueue sends the event to notification, failing with the error of ctx when ctx is done while waiting
+		enqueue := func(event Event) error {
+			n := notice{event: event, span: span.SpanContext()}
+			select {
+			case notification <- n:
+				return nil
+			default:
+				slog.Warn("notification queue is full, waiting for the notifiers", "size", cap(notification), "event", event)
+			}
+			select {
+			case notification <- n:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		for _, event := range events {
+			st.seen.add(event, now)
+			if event.StartAt >= st.lastTs {
+				st.lastTs, st.lastEventID, st.update = event.StartAt, event.EventId, event.Updates
+			}
+			// only other sources report the same earthquake under another id
+			if len(cfg.sourceSpecs()) > 1 {
+				if id, ok := st.quakes.duplicate(event, cfg.Duplicate, now); ok {
+					slog.Info("skip the event", "reason", "duplicate of an event of another source", "of", id, "event", event)
+					continue
+				}
+				st.quakes.add(event, cfg.Duplicate, now)
+			}
+			if cfg.Filter.hasLocation() {
+				lat, lon := cfg.Filter.location()
+				event.Local = locate(event, lat, lon, cfg.SWaveVelocity, cfg.Attenuation)
+			}
+			event.Tsunami = tsunamiRisk(event, cfg.TsunamiMagnitude, cfg.OffshoreKeywords)
+			event.Mainshock = st.aftershocks.mainshock(event, cfg.Aftershock)
+			eventsTotal.WithLabelValues(string(classifySeverity(event))).Inc()
+			if err := store.Save(event); err != nil {
+				slog.Error("save event", "event", event, "err", err)
+			}
+			hub.publish(event)
+			if count := st.swarms.add(event, cfg.Swarm); count > 0 {
+				swarm := event
+				swarm.Swarm = count
+				slog.Info("found a swarm", "num", count, "event", event)
+				if reason := cfg.rejectReason(swarm, serverNow()); reason != "" {
+					slog.Debug("skip the swarm", "reason", reason, "event", swarm)
+				} else if err := enqueue(swarm); err != nil {
+					return true, err
+				}
+			}
+			if reason := cfg.rejectReason(event, serverNow()); reason != "" {
+				slog.Debug("skip the event", "reason", reason, "event", event)
+				continue
+			}
+			notify, revision := st.revisions.check(event, now, cfg.UpdateMagnitudeDelta)
+			if !notify {
+				slog.Debug("skip the event", "reason", "update without significant magnitude change", "event", event)
+				continue
+			}
+			event.Revision = revision
+			if err := enqueue(event); err != nil {
+				return true, err
+			}
+		}
+		if cfg.StateFile != "" && !replaying {
+			if err := saveState(cfg.StateFile, state{LastTs: st.lastTs, LastEventID: st.lastEventID, Updates: st.update}); err != nil {
+				slog.Error("save state", "path", cfg.StateFile, "err", err)
+			}
+		}
+		return true, nil
+	}
+
+	if cfg.Once {
+		if _, err := poll(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	}
+	for {
+		select {
+		case <-ticker.C:
+			found, err := poll()
+			if errors.Is(err, io.EOF) {
+				slog.Info("no more events from the source, loop exiting")
+				return nil
+			}
+			if ctx.Err() != nil {
+				slog.Info("loop exiting")
+				return nil
+			}
+			// the upstream may ask to wait longer than the pace, see retry, within a bound as a bogus
+			// Retry-After would pause the early warnings
+			wait := min(retryAfter(err), max(cfg.MaxInterval, maxPollRetryAfter))
+			ticker.Reset(max(pace.next(found, time.Now()), wait))
+		case next := <-ctl.reloads:
+			cfg, pace = next, newPacer(next)
+			st.cfg = next
+			ticker.Reset(cfg.Interval)
+		case <-ctl.dumps:
+			var lastSuccess time.Time
+			if last := lastQuerySuccess.Load(); last != 0 {
+				lastSuccess = time.Unix(0, last)
+			}
+			slog.Info("state",
+				"lastTs", st.lastTs,
+				"lastEventId", st.lastEventID,
+				"updates", st.update,
+				"seen", len(st.seen.entries),
+				"revisions", len(st.revisions.entries),
+				"quakes", len(st.quakes.entries),
+				"lastQuerySuccess", lastSuccess,
+				"events", counterTotal(eventsTotal),
+				"notifications", counterTotal(notificationsSentTotal),
+				"notificationErrors", counterTotal(notificationErrorsTotal),
+				"notifiers", ctl.notifiers.String(),
+			)
+		case <-ctx.Done():
+			slog.Info("loop exiting")
+			return nil
+		}
+	}
+}
+
+// Run polls the upstream and notifies the configured notifiers until ctx is done,
+// then waits for the pending notifications to be drained.
+func Run(ctx context.Context, cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	queryClient, notifyClient := cfg.QueryClient, cfg.NotifyClient
+	var err error
+	if cfg.OTelEndpoint != "" {
+		shutdown, err := setupTracing(ctx, cfg.OTelEndpoint)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.DrainTimeout)
+			defer cancel()
+			if err := shutdown(shutdownCtx); err != nil {
+				slog.Warn("shutdown tracing", "err", err)
+			}
+		}()
+	}
+	if queryClient == nil {
+		if queryClient, err = newHTTPClient(cfg.QueryHTTP, cfg.UserAgent); err != nil {
+			return err
+		}
+	}
+	if cfg.DumpDir != "" {
+		if queryClient, err = withDumps(queryClient, cfg.DumpDir, cfg.DumpMaxFiles); err != nil {
+			return err
+		}
+	}
+	if notifyClient == nil {
+		if notifyClient, err = newHTTPClient(cfg.NotifyHTTP, cfg.UserAgent); err != nil {
+			return err
+		}
+	}
+	var queue *retryQueue
+	if cfg.RetryWindow > 0 {
+		queue = newRetryQueue(cfg.RetryQueueSize, cfg.RetryWindow, cfg.Filter.MaxEventAge)
+	}
+	pool := &connectionPool{}
+	notifiers, n, err := newNotifier(&cfg, notifyClient, queue, pool)
+	if err != nil {
+		return err
+	}
+	pool.commit()
+	notifier := newSwapNotifier(n, notifiers)
+
+	source, err := newSource(&cfg, queryClient)
+	if err != nil {
+		return err
+	}
+	var store Store = &memoryStore{}
+	// the replayed events would be mixed with the live history
+	if cfg.DB != "" && cfg.Replay == "" {
+		db, err := OpenSQLiteStore(cfg.DB)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		store = db
+	}
+
+	ch := make(chan notice, cfg.QueueSize)
+	hub := newBroadcaster()
+
+	srv := servers{}
+	srv.handle(cfg.MetricsAddr, "/metrics", promhttp.Handler())
+	srv.handle(cfg.HealthAddr, "/healthz", http.HandlerFunc(healthzHandler))
+	srv.handle(cfg.HealthAddr, "/readyz", readyzHandler(cfg.HealthStaleness))
+	srv.handle(cfg.APIAddr, "/events", eventsHandler(store))
+	srv.handle(cfg.APIAddr, "/events.geojson", geoJSONHandler(store))
+	srv.handle(cfg.APIAddr, "/events.csv", csvHandler(store))
+	srv.handle(cfg.APIAddr, "/events/stream", streamHandler(hub))
+	srv.handle(cfg.APIAddr, "/", http.HandlerFunc(dashboardHandler))
+	srv.handle(cfg.PprofAddr, "/debug/pprof/", http.HandlerFunc(pprof.Index))
+	srv.handle(cfg.PprofAddr, "/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	srv.handle(cfg.PprofAddr, "/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	srv.handle(cfg.PprofAddr, "/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	srv.handle(cfg.PprofAddr, "/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	srv.run(ctx)
+
+	var (
+		wg sync.WaitGroup
+		// loopErr is the error of the poll of -once
+		loopErr error
+	)
+	// the retry queue and the reloads stop with the notifications, when ctx is done or the source is exhausted
+	queueCtx, stopQueue := context.WithCancel(ctx)
+	defer stopQueue()
+	if queue != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			supervise(queueCtx, "retry queue", func() {
+				queue.run(queueCtx)
+			})
+		}()
+	}
+	reloads := make(chan *Config, 1)
+	if cfg.Reload != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				var next *Config
+				select {
+				case next = <-cfg.Reload:
+				case <-queueCtx.Done():
+					return
+				}
+				if err := next.Validate(); err != nil {
+					slog.Error("reload config", "err", err)
+					continue
+				}
+				notifiers, n, err := newNotifier(next, notifyClient, queue, pool)
+				if err != nil {
+					slog.Error("reload config", "err", err)
+					continue
+				}
+				retired := pool.commit()
+				inflight := notifier.swap(n, notifiers)
+				if len(retired) > 0 {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						closeRetired(queueCtx, retired, inflight, queue)
+					}()
+				}
+				// the loop takes the newest configuration only
+				select {
+				case <-reloads:
+				default:
+				}
+				reloads <- next
+				slog.Info("config reloaded", "notifiers", len(notifiers))
+			}
+		}()
+	}
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer stopQueue()
+		supervise(ctx, "notification", func() {
+			notification(ctx, ch, notifier, cfg.DrainTimeout)
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		if cfg.Simulate {
+			select {
+			case ch <- notice{event: simulatedEvent(&cfg, time.Now())}:
+			case <-ctx.Done():
+				return
+			}
+			if cfg.SimulateExit {
+				return
+			}
+		}
+		st := newLoopState(&cfg, source)
+		supervise(ctx, "loop", func() {
+			loopErr = loop(ctx, st, source, store, hub, loopControl{reloads: reloads, dumps: cfg.Dump, notifiers: notifier}, ch)
+		})
+	}()
+	wg.Wait()
+	if err := pool.connections().Close(); err != nil {
+		slog.Warn("close notifiers", "err", err)
+	}
+	return loopErr
+}
+
+// closeRetired closes the notifiers replaced by a reload once their in-flight sends are done and queue does not
+// hold their retries anymore, or when ctx is done.
+func closeRetired(ctx context.Context, retired MultiNotifier, inflight *sync.WaitGroup, queue *retryQueue) {
+	inflight.Wait()
+	for queue != nil && queue.holds(retired) {
+		select {
+		case <-ctx.Done():
+			// the retry queue drops its pending notifications on exit
+			queue = nil
+		case <-time.After(retryQueueBaseDelay):
+		}
+	}
+	if err := retired.Close(); err != nil {
+		slog.Warn("close retired notifiers", "err", err)
+	}
+}
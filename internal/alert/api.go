@@ -0,0 +1,192 @@
+package alert
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultEventsLimit = 20
+	maxEventsLimit     = 1000
+)
+
+// recentEvents returns the latest events of store for a GET request, newest first, as many as its limit query
+// parameter. It answers the request itself and returns false when the request is invalid or store fails.
+func recentEvents(w http.ResponseWriter, r *http.Request, store Store) ([]Event, bool) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	limit := defaultEventsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit should be a positive integer", http.StatusBadRequest)
+			return nil, false
+		}
+		limit = min(n, maxEventsLimit)
+	}
+	events, err := store.Recent(limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return events, true
+}
+
+// eventsHandler serves the latest events of store as a JSON array, newest first.
+func eventsHandler(store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		events, ok := recentEvents(w, r, store)
+		if !ok {
+			return
+		}
+		if events == nil {
+			events = []Event{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(events)
+	}
+}
+
+// geoJSONFeature is an event as a GeoJSON Point feature at its epicenter.
+type geoJSONFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string     `json:"type"`
+		Coordinates [2]float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		EventId   int     `json:"eventId"`
+		Magnitude float64 `json:"magnitude"`
+		Depth     float64 `json:"depth"`
+		Epicenter string  `json:"epicenter"`
+		// Time is the RFC 3339 UTC time the event started at.
+		Time string `json:"time"`
+	} `json:"properties"`
+}
+
+// geoJSONHandler serves the latest events of store as a GeoJSON FeatureCollection of points, newest first,
+// for the mapping libraries like Leaflet.
+func geoJSONHandler(store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		events, ok := recentEvents(w, r, store)
+		if !ok {
+			return
+		}
+		features := make([]geoJSONFeature, len(events))
+		for i, event := range events {
+			f := &features[i]
+			f.Type = "Feature"
+			f.Geometry.Type = "Point"
+			f.Geometry.Coordinates = [2]float64{event.Longitude, event.Latitude}
+			f.Properties.EventId = event.EventId
+			f.Properties.Magnitude = event.Magnitude
+			f.Properties.Depth = event.Depth
+			f.Properties.Epicenter = event.Epicenter
+			f.Properties.Time = time.UnixMilli(event.StartAt).UTC().Format(time.RFC3339)
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"type": "FeatureCollection", "features": features})
+	}
+}
+
+// csvHeader names the columns of the events exported by csvHandler.
+var csvHeader = []string{"event_id", "time", "magnitude", "depth", "latitude", "longitude", "epicenter"}
+
+// csvHandler streams every event of store as CSV, oldest first, for the spreadsheets.
+func csvHandler(store Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="events.csv"`)
+		cw := csv.NewWriter(w)
+		_ = cw.Write(csvHeader)
+		err := store.Each(func(event Event) error {
+			return cw.Write(csvRecord(event))
+		})
+		cw.Flush()
+		if err == nil {
+			err = cw.Error()
+		}
+		if err != nil {
+			// the status has been sent with the first rows, the truncated export can only be logged
+			slog.Warn("export events as csv", "err", err)
+		}
+	}
+}
+
+// csvRecord is the row of event under csvHeader.
+func csvRecord(event Event) []string {
+	return []string{
+		strconv.Itoa(event.EventId),
+		time.UnixMilli(event.StartAt).UTC().Format(time.RFC3339),
+		strconv.FormatFloat(event.Magnitude, 'f', -1, 64),
+		strconv.FormatFloat(event.Depth, 'f', -1, 64),
+		strconv.FormatFloat(event.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(event.Longitude, 'f', -1, 64),
+		event.Epicenter,
+	}
+}
+
+// streamKeepAlive is the interval of the comments keeping an idle event stream open through proxies.
+const streamKeepAlive = 30 * time.Second
+
+// streamHandler streams the events published by hub as Server-Sent Events, one JSON event per data line.
+func streamHandler(hub *broadcaster) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+		events, unsubscribe := hub.subscribe()
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(streamKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				var data []byte
+				if data, err = json.Marshal(event); err != nil {
+					slog.Error("encode streamed event", "event", event, "err", err)
+					continue
+				}
+				_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+			case <-keepAlive.C:
+				_, err = io.WriteString(w, ": keep-alive\n\n")
+			}
+			if err != nil {
+				// the client is gone
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+const (
+	// defaultCriticalMagnitude is the magnitude from which Bark sends critical alerts by default.
+	defaultCriticalMagnitude = 6
+	defaultBarkGroup         = "earthquake"
+)
+
+// BarkNotifier pushes events to the Bark app through api.day.app, on the device of each key.
+type BarkNotifier struct {
+	Keys []string
+	// CriticalMagnitude is the magnitude from which the alert is critical, ringing with an alarm
+	// even in Do Not Disturb.
+	CriticalMagnitude float64
+	// Group collapses the alerts in the notification list, Icon is the url of their icon, empty for the default.
+	Group     string
+	Icon      string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+// barkResponse is the response of the Bark server, Code mirrors an HTTP status with 200 for success.
+type barkResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (b *BarkNotifier) String() string {
+	return "bark"
+}
+
+// params returns the query parameters of an alert, with the level and sound by magnitude, critical for
+// possible tsunamis, time sensitive alerts break through focus modes.
+func (b *BarkNotifier) params(event Event) url.Values {
+	magnitude := event.Magnitude
+	var params url.Values
+	switch {
+	case magnitude >= b.CriticalMagnitude || event.Tsunami:
+		params = url.Values{"level": {"critical"}, "sound": {"alarm"}, "volume": {"10"}}
+	case magnitude >= 4.5:
+		params = url.Values{"level": {"timeSensitive"}, "sound": {"shake"}}
+	default:
+		params = url.Values{"level": {"active"}}
+	}
+	if b.Group != "" {
+		params.Set("group", b.Group)
+	}
+	if b.Icon != "" {
+		params.Set("icon", b.Icon)
+	}
+	return params
+}
+
+// maskKey keeps the beginning of a key to tell the devices apart in logs without leaking it.
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return "***"
+	}
+	return key[:4] + "***"
+}
+
+// Send pushes the event to every device concurrently, a failed device does not stop the others.
+func (b *BarkNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := b.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	mapURL, err := b.Formatter.mapURL(event)
+	if err != nil {
+		return err
+	}
+	values := b.params(event)
+	if mapURL != "" {
+		// tapping the alert opens the map
+		values.Set("url", mapURL)
+	}
+	params := values.Encode()
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(b.Keys))
+	)
+	for i, key := range b.Keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			err := recovered("notifier bark", func() error {
+				return b.send(ctx, key, title, body, params)
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("key %s: %w", maskKey(key), err)
+				return
+			}
+			slog.Info("notification successfully", "notifier", "bark", "key", maskKey(key))
+		}(i, key)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (b *BarkNotifier) send(ctx context.Context, key, title, body, params string) error {
+	// the title and body are path segments, their "/", "#", "?" and "%" would corrupt the url
+	u := fmt.Sprintf("https://api.day.app/%s/%s/%s?%s",
+		url.PathEscape(key), url.PathEscape(title), url.PathEscape(body), params)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	response, err := b.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if err = rateLimited(response); err != nil {
+		return err
+	}
+	var resp barkResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("bark: decode response with status %d: %w", response.StatusCode, err)
+	}
+	if resp.Code != http.StatusOK {
+		// the code mirrors an HTTP status, a server error like a failed push to APNs is worth resending
+		return rejected(max(response.StatusCode, resp.Code), fmt.Errorf("bark: code %d: %s", resp.Code, resp.Message))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// redirectTransport sends every request to the test server at target, whatever its host.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestBarkEscapesPath(t *testing.T) {
+	var path, level string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, level = r.URL.EscapedPath(), r.URL.Query().Get("level")
+		_, _ = w.Write([]byte(`{"code":200,"message":"success"}`))
+	}))
+	defer srv.Close()
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formatter, err := NewFormatter("M{{.Magnitude}} 50%/#1?\n{{.Epicenter}}", "zh", "", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &BarkNotifier{
+		Keys:              []string{"k/ey"},
+		CriticalMagnitude: defaultCriticalMagnitude,
+		Client:            &http.Client{Transport: redirectTransport{target: target}},
+		Formatter:         formatter,
+	}
+	if err = b.Send(context.Background(), Event{Magnitude: 5, Epicenter: "四川/汶川 #2"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "/k%2Fey/M5%2050%25%2F%231%3F/%E5%9B%9B%E5%B7%9D%2F%E6%B1%B6%E5%B7%9D%20%232"
+	if path != want {
+		t.Errorf("got path %s, want %s", path, want)
+	}
+	// the "?" of the title must not cut the path short of the query
+	if level != "timeSensitive" {
+		t.Errorf("got level %q, want timeSensitive", level)
+	}
+}
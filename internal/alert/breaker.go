@@ -0,0 +1,76 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// errCircuitOpen is returned by the polls skipped while the circuit of a BreakerSource is open.
+var errCircuitOpen = errors.New("circuit open")
+
+// circuit states, the values of the earthquake_circuit_state metric.
+const (
+	circuitClosed = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitState is the state of the circuit of the source, for the metrics and the readiness.
+var circuitState atomic.Int32
+
+// BreakerSource opens its circuit after FailureThreshold consecutive failures of Source, skipping the polls
+// during Cooldown to spare the failing upstream. Then the next poll probes it, closing the circuit when
+// it succeeds and opening it again when it fails.
+type BreakerSource struct {
+	Source
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	state    int32
+	failures int
+	openedAt time.Time
+}
+
+func (b *BreakerSource) Poll(ctx context.Context, since int64) ([]Event, error) {
+	now := time.Now()
+	if b.state == circuitOpen {
+		if now.Sub(b.openedAt) < b.Cooldown {
+			return nil, errCircuitOpen
+		}
+		b.setState(circuitHalfOpen)
+		slog.Info("circuit half-open, probing the upstream")
+	}
+	events, err := b.Source.Poll(ctx, since)
+	if err == nil {
+		if b.state != circuitClosed {
+			slog.Info("upstream recovered, circuit closed")
+		}
+		b.failures = 0
+		b.setState(circuitClosed)
+		return events, nil
+	}
+	if errors.Is(err, io.EOF) || ctx.Err() != nil {
+		return nil, err
+	}
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.FailureThreshold {
+		slog.Warn("upstream failing, circuit open, skipping the polls", "failures", b.failures, "cooldown", b.Cooldown, "err", err)
+		b.openedAt = now
+		b.setState(circuitOpen)
+	}
+	return nil, err
+}
+
+func (b *BreakerSource) late() bool {
+	return publishesLate(b.Source)
+}
+
+func (b *BreakerSource) setState(state int32) {
+	b.state = state
+	circuitState.Store(state)
+	circuitStateGauge.Set(float64(state))
+}
@@ -0,0 +1,41 @@
+package alert
+
+import "sync"
+
+// subscriberBuffer is the number of events buffered for a subscriber of a broadcaster.
+const subscriberBuffer = 16
+
+// broadcaster fans the events out to its subscribers, a subscriber whose buffer is full misses the events
+// instead of blocking the loop.
+type broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{subs: make(map[chan Event]struct{})}
+}
+
+// subscribe returns the channel of the events published from now on, and the function to unsubscribe.
+func (b *broadcaster) subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+}
+
+func (b *broadcaster) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
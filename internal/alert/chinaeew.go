@@ -0,0 +1,68 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const chinaEEWURL = "https://mobile-new.chinaeew.cn/v1/earlywarnings"
+
+// codeSuccess is the Response.Code of a successful upstream request.
+const codeSuccess = 0
+
+// Response is the envelope of the China EEW feed.
+type Response struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Data    []Event `json:"data"`
+}
+
+// ChinaEEWSource polls the early warnings of the China Earthquake Networks Center.
+type ChinaEEWSource struct {
+	URL            string
+	Client         *http.Client
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+	// Updates is the updates parameter of the queries, a negative value sends the revision of the newest event.
+	Updates int
+
+	// updates is the revision of the newest event, sent back on the next poll.
+	updates    int
+	validators validators
+}
+
+func (s *ChinaEEWSource) Poll(ctx context.Context, since int64) ([]Event, error) {
+	resp, err := query[Response](ctx, s.Client, s.url(since), &s.validators, s.MaxRetries, s.RetryBaseDelay)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code != codeSuccess {
+		return nil, fmt.Errorf("code %d: %s", resp.Code, resp.Message)
+	}
+	if len(resp.Data) > 0 {
+		s.updates = resp.Data[0].Updates
+	}
+	return resp.Data, nil
+}
+
+// probe queries the feed once, leaving the state of the polls as is.
+func (s *ChinaEEWSource) probe(ctx context.Context, since int64) error {
+	resp, err := query[Response](ctx, s.Client, s.url(since), nil, 0, 0)
+	if err != nil {
+		return err
+	}
+	if resp.Code != codeSuccess {
+		return fmt.Errorf("code %d: %s", resp.Code, resp.Message)
+	}
+	return nil
+}
+
+func (s *ChinaEEWSource) url(since int64) string {
+	updates := s.Updates
+	if updates < 0 {
+		updates = s.updates
+	}
+	return fmt.Sprintf("%s?start_at=%d&updates=%d", s.URL, since, updates)
+}
@@ -0,0 +1,34 @@
+package alert
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// clockSkewWarning is the skew of the local clock logged as a warning.
+const clockSkewWarning = time.Minute
+
+// clockSkew is how far the clock of the upstream is ahead of the local clock in nanoseconds,
+// measured from the Date header of its last response, 0 when the header is missing.
+var clockSkew atomic.Int64
+
+// recordServerDate measures the clock skew from the Date header of a response received at the local time received.
+func recordServerDate(header http.Header, received time.Time) {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		clockSkew.Store(0)
+		return
+	}
+	// the Date header has a resolution of a second, a smaller skew is only its truncation
+	skew := date.Sub(received).Round(time.Second)
+	if previous := time.Duration(clockSkew.Swap(int64(skew))); skew.Abs() >= clockSkewWarning && previous.Abs() < clockSkewWarning {
+		slog.Warn("the local clock is skewed from the upstream, using the upstream time", "skew", skew)
+	}
+}
+
+// serverNow is the current time of the upstream, the local time corrected by the clock skew.
+func serverNow() time.Time {
+	return time.Now().Add(time.Duration(clockSkew.Load()))
+}
@@ -0,0 +1,660 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every setting of the alerter, loaded from a YAML file and overridden by command-line flags.
+type Config struct {
+	ConfigFile string `yaml:"-"`
+	// ShowVersion prints the version and exits.
+	ShowVersion bool `yaml:"-"`
+
+	// Source is a comma-separated list of sources by priority to fail over, each one like usgs or
+	// usgs=<url>, SourceURL is the url of the first one.
+	Source    string        `yaml:"source"`
+	SourceURL string        `yaml:"source_url"`
+	Interval  time.Duration `yaml:"interval"`
+	StateFile string        `yaml:"state_file"`
+	// Updates is the updates parameter of the queries of chinaeew, -1 means the revision of the newest event.
+	Updates int `yaml:"updates"`
+	// FailoverThreshold is the number of consecutive failed polls of a source that fails over to the next one,
+	// the failing primary is probed every FailbackInterval to fail back.
+	FailoverThreshold int           `yaml:"failover_threshold"`
+	FailbackInterval  time.Duration `yaml:"failback_interval"`
+	// BreakerThreshold is the number of consecutive failed polls opening the circuit for BreakerCooldown,
+	// see BreakerSource, 0 disables the breaker, the default as the skipped polls could miss an earthquake.
+	BreakerThreshold int           `yaml:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`
+	// Replay replays the events recorded in the file instead of polling Source, see LoadReplaySource,
+	// leaving DB and StateFile untouched.
+	Replay        string `yaml:"replay"`
+	ReplayInstant bool   `yaml:"replay_instant"`
+	// DumpDir saves the raw responses of the upstream to files of the directory, keeping the newest DumpMaxFiles.
+	DumpDir      string `yaml:"dump_dir"`
+	DumpMaxFiles int    `yaml:"dump_max_files"`
+
+	// DB is the SQLite database file keeping the history of events, empty means no history.
+	DB string `yaml:"db"`
+
+	// MinInterval is the interval shortly after an event is found, MaxInterval bounds the backoff
+	// while no event is found, either is ignored when it does not make polls faster or slower. Both default to 0,
+	// polling at Interval, as a longer interval delays the first alert of a new earthquake.
+	MinInterval time.Duration `yaml:"min_interval"`
+	MaxInterval time.Duration `yaml:"max_interval"`
+
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+	// RetryWindow is how long a failed notification is resent for, at most RetryQueueSize of them are pending,
+	// 0 means no resend.
+	RetryWindow    time.Duration `yaml:"retry_window"`
+	RetryQueueSize int           `yaml:"retry_queue_size"`
+	// QueueSize is the number of events buffered between the polling loop and the notifiers.
+	QueueSize int `yaml:"queue_size"`
+
+	LogFormat string `yaml:"log_format"`
+	LogLevel  string `yaml:"log_level"`
+
+	// QueryHTTP configures the client of the source and NotifyHTTP the client of the notifiers, the top-level
+	// insecure, ca_cert, proxy and http_timeout of the config file apply to both, see LoadConfig.
+	QueryHTTP  HTTPConfig `yaml:"query_http"`
+	NotifyHTTP HTTPConfig `yaml:"notify_http"`
+	// UserAgent of every request, empty means earthquake-alert/<version>.
+	UserAgent string `yaml:"user_agent"`
+	// QueryClient and NotifyClient replace the clients built from QueryHTTP and NotifyHTTP when set.
+	QueryClient  *http.Client `yaml:"-"`
+	NotifyClient *http.Client `yaml:"-"`
+	// Reload receives the configurations swapped in while running: the filters, the poll interval, the notifiers
+	// and their messages are reloaded, the source, the servers, the store and the HTTP clients take a restart.
+	Reload <-chan *Config `yaml:"-"`
+	// Dump receives the requests to log a snapshot of the state.
+	Dump <-chan struct{} `yaml:"-"`
+
+	MetricsAddr     string        `yaml:"metrics_addr"`
+	HealthAddr      string        `yaml:"health_addr"`
+	HealthStaleness time.Duration `yaml:"health_staleness"`
+	PprofAddr       string        `yaml:"pprof_addr"`
+	APIAddr         string        `yaml:"api_addr"`
+	// OTelEndpoint is the OTLP/HTTP url the traces of the polls and the notifications are exported to, empty means none.
+	OTelEndpoint string `yaml:"otel_endpoint"`
+
+	MaxRetries     int           `yaml:"max_retries"`
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay"`
+
+	Filter FilterConfig `yaml:"filter"`
+	// Duplicate drops an event that is the same earthquake as another one reported under a different id.
+	Duplicate DuplicateConfig `yaml:"duplicate"`
+	// UpdateMagnitudeDelta is the change of magnitude from which an update of a notified event is notified again, once.
+	UpdateMagnitudeDelta float64 `yaml:"update_magnitude_delta"`
+	// TsunamiMagnitude is the magnitude from which an offshore event, whose epicenter has one of
+	// OffshoreKeywords as a word, is alerted as a possible tsunami, 0 means never.
+	TsunamiMagnitude float64  `yaml:"tsunami_magnitude"`
+	OffshoreKeywords []string `yaml:"offshore_keywords"`
+	// Swarm alerts the clusters of events, see SwarmConfig.
+	Swarm SwarmConfig `yaml:"swarm"`
+	// Aftershock annotates the possible aftershocks, see AftershockConfig.
+	Aftershock AftershockConfig `yaml:"aftershock"`
+	// SWaveVelocity is the S-wave velocity in km/s to estimate its arrival at the configured location.
+	SWaveVelocity float64 `yaml:"s_wave_velocity"`
+	// Attenuation estimates the intensity at the configured location, only settable in the config file.
+	Attenuation Attenuation `yaml:"attenuation"`
+
+	// MessageTemplate is the text/template of messages, see Formatter.
+	MessageTemplate string `yaml:"message_template"`
+	// MapURL is the text/template of the link to the epicenter on a map, executed with the event, empty means no link.
+	MapURL string `yaml:"map_url"`
+	// Lang selects the built-in message template, zh or en.
+	Lang     string `yaml:"lang"`
+	Timezone string `yaml:"timezone"`
+
+	// QuietStart and QuietEnd like 23:00 and 07:00 in Timezone hold back the events below
+	// QuietOverrideMagnitude, no quiet hours when empty.
+	QuietStart             string  `yaml:"quiet_start"`
+	QuietEnd               string  `yaml:"quiet_end"`
+	QuietOverrideMagnitude float64 `yaml:"quiet_override_magnitude"`
+
+	// MaxNotificationsPerMinute drops the notifications beyond it, except those of events of at least
+	// RateLimitBypassMagnitude, 0 means no limit.
+	MaxNotificationsPerMinute int     `yaml:"max_notifications_per_minute"`
+	RateLimitBypassMagnitude  float64 `yaml:"rate_limit_bypass_magnitude"`
+
+	DryRun bool `yaml:"dry_run"`
+	// Once polls the source a single time, sends the notifications and exits, for cron jobs.
+	Once bool `yaml:"once"`
+	// History queries the chinaeew events between HistoryStart and HistoryEnd in Timezone, like 2024-01-02
+	// or 2024-01-02 15:04, instead of polling, HistoryEnd defaults to now.
+	History      bool   `yaml:"-"`
+	HistoryStart string `yaml:"-"`
+	HistoryEnd   string `yaml:"-"`
+	// Simulate sends a synthetic event to the notifiers on startup, then exits when SimulateExit is set.
+	Simulate          bool    `yaml:"simulate"`
+	SimulateMagnitude float64 `yaml:"simulate_magnitude"`
+	SimulateEpicenter string  `yaml:"simulate_epicenter"`
+	SimulateExit      bool    `yaml:"simulate_exit"`
+
+	Notifiers []NotifierConfig `yaml:"notifiers"`
+	// Subscriptions notify their own notifiers of the events passing their own filter, besides Notifiers
+	// which then only get the events passing Filter. They are configured in the config file only.
+	Subscriptions []SubscriptionConfig `yaml:"subscriptions"`
+
+	// Notifiers configured by command-line flags, appended to Notifiers when set.
+	Bark       NotifierConfig `yaml:"-"`
+	Telegram   NotifierConfig `yaml:"-"`
+	Webhook    NotifierConfig `yaml:"-"`
+	Pushover   NotifierConfig `yaml:"-"`
+	Ntfy       NotifierConfig `yaml:"-"`
+	DingTalk   NotifierConfig `yaml:"-"`
+	WeCom      NotifierConfig `yaml:"-"`
+	Feishu     NotifierConfig `yaml:"-"`
+	ServerChan NotifierConfig `yaml:"-"`
+	Gotify     NotifierConfig `yaml:"-"`
+	Email      NotifierConfig `yaml:"-"`
+	Discord    NotifierConfig `yaml:"-"`
+	Slack      NotifierConfig `yaml:"-"`
+	MQTT       NotifierConfig `yaml:"-"`
+	NATS       NotifierConfig `yaml:"-"`
+	Kafka      NotifierConfig `yaml:"-"`
+	Redis      NotifierConfig `yaml:"-"`
+}
+
+// HTTPConfig configures an HTTP client, see newHTTPClient.
+type HTTPConfig struct {
+	Insecure bool   `yaml:"insecure"`
+	CACert   string `yaml:"ca_cert"`
+	Proxy    string `yaml:"proxy"`
+	// Timeout bounds each request including reading its response, every retry has its own.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func (h HTTPConfig) validate() error {
+	if h.Proxy != "" {
+		if _, err := parseProxy(h.Proxy); err != nil {
+			return err
+		}
+	}
+	if h.Timeout <= 0 {
+		return errors.New("timeout should be positive")
+	}
+	return nil
+}
+
+// FilterConfig decides which events are worth a notification.
+type FilterConfig struct {
+	MaxEventAge  time.Duration `yaml:"max_event_age"`
+	MinMagnitude float64       `yaml:"min_magnitude"`
+	// Lat and Lon are your location, nil when not set. The distance, the S-wave arrival and the intensity need both.
+	Lat      *float64 `yaml:"lat"`
+	Lon      *float64 `yaml:"lon"`
+	RadiusKm float64  `yaml:"radius_km"`
+	// BBox keeps only the events inside the box [minLat, minLon, maxLat, maxLon] in degrees, which crosses the
+	// antimeridian when minLon is above maxLon. It excludes RadiusKm.
+	BBox []float64 `yaml:"bbox"`
+	// Regions keeps only the events whose epicenter contains one of them, case-insensitively.
+	Regions []string `yaml:"regions"`
+	// InsideNetOnly drops the events outside the monitoring network, whose InsideNet is 0.
+	InsideNetOnly bool `yaml:"inside_net_only"`
+	// MinStations holds back the preliminary events reported by fewer stations, 0 means no minimum.
+	MinStations int `yaml:"min_stations"`
+}
+
+// NotifierConfig configures one notification backend, the fields used depend on Type.
+type NotifierConfig struct {
+	Type string `yaml:"type"`
+	// MinMagnitude is the minimum magnitude of the events sent to this notifier, on top of the filter.
+	MinMagnitude float64 `yaml:"min_magnitude,omitempty"`
+
+	Key string `yaml:"key,omitempty"`
+	// Keys are more keys of bark, for several devices.
+	Keys    []string `yaml:"keys,omitempty"`
+	Token   string   `yaml:"token,omitempty"`
+	Chat    string   `yaml:"chat,omitempty"`
+	User    string   `yaml:"user,omitempty"`
+	URL     string   `yaml:"url,omitempty"`
+	Headers []string `yaml:"headers,omitempty"`
+	Secret  string   `yaml:"secret,omitempty"`
+	// Group and Icon of bark alerts, an empty Group means earthquake.
+	Group string `yaml:"group,omitempty"`
+	Icon  string `yaml:"icon,omitempty"`
+	// Host, Port, Username, Password, From and To configure the SMTP server of email, Port 0 means 587.
+	Host     string   `yaml:"host,omitempty"`
+	Port     int      `yaml:"port,omitempty"`
+	Username string   `yaml:"username,omitempty"`
+	Password string   `yaml:"password,omitempty"`
+	From     string   `yaml:"from,omitempty"`
+	To       []string `yaml:"to,omitempty"`
+	// Topic and QoS of the messages published by mqtt to the broker at URL, Topic is the subject of nats
+	// and the channel of redis.
+	Topic string `yaml:"topic,omitempty"`
+	QoS   int    `yaml:"qos,omitempty"`
+	// ClientID is the mqtt client ID, empty for a random one.
+	ClientID string `yaml:"client_id,omitempty"`
+	// Brokers are the addresses of the bootstrap brokers of kafka, which produces to Topic.
+	Brokers []string `yaml:"brokers,omitempty"`
+	// EmergencyMagnitude is the magnitude from which pushover sends emergency notifications and bark
+	// critical alerts, 0 means 7 for pushover and 6 for bark.
+	EmergencyMagnitude float64 `yaml:"emergency_magnitude,omitempty"`
+}
+
+// DefaultConfig returns the config used when nothing is set.
+func DefaultConfig() *Config {
+	return &Config{
+		Source:                   "chinaeew",
+		Interval:                 3 * time.Second,
+		Updates:                  -1,
+		FailoverThreshold:        3,
+		FailbackInterval:         time.Minute,
+		BreakerCooldown:          2 * time.Minute,
+		DumpMaxFiles:             500,
+		MaxRetries:               3,
+		RetryBaseDelay:           500 * time.Millisecond,
+		QueryHTTP:                HTTPConfig{Timeout: 10 * time.Second},
+		NotifyHTTP:               HTTPConfig{Timeout: 10 * time.Second},
+		DrainTimeout:             10 * time.Second,
+		RetryWindow:              5 * time.Minute,
+		RetryQueueSize:           100,
+		QueueSize:                8,
+		HealthStaleness:          time.Minute,
+		Filter:                   FilterConfig{MaxEventAge: 30 * time.Minute},
+		SWaveVelocity:            3.5,
+		UpdateMagnitudeDelta:     0.5,
+		Duplicate:                DuplicateConfig{Precision: 0.5, Window: time.Minute, MagnitudeTolerance: 1},
+		TsunamiMagnitude:         6.5,
+		OffshoreKeywords:         slices.Clone(defaultOffshoreKeywords),
+		Swarm:                    SwarmConfig{RadiusKm: 50, Window: time.Hour},
+		Aftershock:               AftershockConfig{Window: 72 * time.Hour},
+		Attenuation:              defaultAttenuation,
+		Lang:                     "zh",
+		MapURL:                   DefaultMapURL,
+		Timezone:                 "Asia/Shanghai",
+		QuietOverrideMagnitude:   5,
+		RateLimitBypassMagnitude: 6,
+		SimulateMagnitude:        5,
+		LogFormat:                "text",
+		LogLevel:                 "info",
+		Bark:                     NotifierConfig{Type: "bark"},
+		Telegram:                 NotifierConfig{Type: "telegram"},
+		Webhook:                  NotifierConfig{Type: "webhook"},
+		Pushover:                 NotifierConfig{Type: "pushover"},
+		Ntfy:                     NotifierConfig{Type: "ntfy"},
+		DingTalk:                 NotifierConfig{Type: "dingtalk"},
+		WeCom:                    NotifierConfig{Type: "wecom"},
+		Feishu:                   NotifierConfig{Type: "feishu"},
+		ServerChan:               NotifierConfig{Type: "serverchan"},
+		Gotify:                   NotifierConfig{Type: "gotify"},
+		Email:                    NotifierConfig{Type: "email", Port: defaultSMTPPort},
+		Discord:                  NotifierConfig{Type: "discord"},
+		Slack:                    NotifierConfig{Type: "slack"},
+		MQTT:                     NotifierConfig{Type: "mqtt"},
+		NATS:                     NotifierConfig{Type: "nats"},
+		Kafka:                    NotifierConfig{Type: "kafka"},
+		Redis:                    NotifierConfig{Type: "redis"},
+	}
+}
+
+// LoadConfig reads the YAML file at path on top of the default config.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+	shared := struct {
+		Insecure *bool          `yaml:"insecure"`
+		CACert   *string        `yaml:"ca_cert"`
+		Proxy    *string        `yaml:"proxy"`
+		Timeout  *time.Duration `yaml:"http_timeout"`
+	}{}
+	if err = yaml.Unmarshal(data, &shared); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	// the shared settings are the defaults of both clients, query_http and notify_http override them
+	for _, h := range []*HTTPConfig{&cfg.QueryHTTP, &cfg.NotifyHTTP} {
+		if shared.Insecure != nil {
+			h.Insecure = *shared.Insecure
+		}
+		if shared.CACert != nil {
+			h.CACert = *shared.CACert
+		}
+		if shared.Proxy != nil {
+			h.Proxy = *shared.Proxy
+		}
+		if shared.Timeout != nil {
+			h.Timeout = *shared.Timeout
+		}
+	}
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	cfg.ConfigFile = path
+	return cfg, nil
+}
+
+// sourceSpec is a source of the list of Source, name or name=url.
+type sourceSpec struct {
+	name, url string
+}
+
+// sourceSpecs splits Source into the sources by priority, the first one defaults to SourceURL.
+func (c *Config) sourceSpecs() []sourceSpec {
+	var specs []sourceSpec
+	for _, s := range strings.Split(c.Source, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		name, url, _ := strings.Cut(s, "=")
+		if len(specs) == 0 && url == "" {
+			url = c.SourceURL
+		}
+		specs = append(specs, sourceSpec{name: name, url: url})
+	}
+	return specs
+}
+
+// notifierConfigs returns the notifiers of the config file followed by those set by flags.
+func (c *Config) notifierConfigs() []NotifierConfig {
+	configs := append([]NotifierConfig(nil), c.Notifiers...)
+	if len(c.Bark.barkKeys()) > 0 {
+		configs = append(configs, c.Bark)
+	}
+	if c.Telegram.Token != "" || c.Telegram.Chat != "" {
+		configs = append(configs, c.Telegram)
+	}
+	if c.Webhook.URL != "" {
+		configs = append(configs, c.Webhook)
+	}
+	if c.Pushover.Token != "" || c.Pushover.User != "" {
+		configs = append(configs, c.Pushover)
+	}
+	if c.Ntfy.URL != "" {
+		configs = append(configs, c.Ntfy)
+	}
+	if c.DingTalk.URL != "" {
+		configs = append(configs, c.DingTalk)
+	}
+	if c.WeCom.URL != "" {
+		configs = append(configs, c.WeCom)
+	}
+	if c.Feishu.URL != "" {
+		configs = append(configs, c.Feishu)
+	}
+	if c.ServerChan.Key != "" {
+		configs = append(configs, c.ServerChan)
+	}
+	if c.Gotify.URL != "" || c.Gotify.Token != "" {
+		configs = append(configs, c.Gotify)
+	}
+	if c.Email.Host != "" {
+		configs = append(configs, c.Email)
+	}
+	if c.Discord.URL != "" {
+		configs = append(configs, c.Discord)
+	}
+	if c.Slack.URL != "" {
+		configs = append(configs, c.Slack)
+	}
+	if c.MQTT.URL != "" || c.MQTT.Topic != "" {
+		configs = append(configs, c.MQTT)
+	}
+	if c.NATS.URL != "" || c.NATS.Topic != "" {
+		configs = append(configs, c.NATS)
+	}
+	if len(c.Kafka.Brokers) > 0 || c.Kafka.Topic != "" {
+		configs = append(configs, c.Kafka)
+	}
+	if c.Redis.URL != "" || c.Redis.Topic != "" {
+		configs = append(configs, c.Redis)
+	}
+	return configs
+}
+
+// Validate checks the config is complete and consistent.
+func (c *Config) Validate() error {
+	if len(c.sourceSpecs()) == 0 {
+		return errors.New("source should have a value")
+	}
+	for _, s := range c.sourceSpecs() {
+		if _, ok := sources[s.name]; !ok {
+			return fmt.Errorf("unknown source %q", s.name)
+		}
+	}
+	if c.Updates < -1 {
+		return errors.New("updates should be -1 or more")
+	}
+	if c.DumpDir != "" && c.DumpMaxFiles <= 0 {
+		return errors.New("dump-max-files should be positive")
+	}
+	if c.BreakerThreshold < 0 || c.BreakerThreshold > 0 && c.BreakerCooldown <= 0 {
+		return errors.New("breaker-threshold should not be negative and breaker-cooldown positive")
+	}
+	if c.FailoverThreshold <= 0 {
+		return errors.New("failover-threshold should be positive")
+	}
+	if _, err := NewLogger(io.Discard, c.LogFormat, c.LogLevel); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	if err := c.QueryHTTP.validate(); err != nil {
+		return fmt.Errorf("query http: %w", err)
+	}
+	if err := c.NotifyHTTP.validate(); err != nil {
+		return fmt.Errorf("notify http: %w", err)
+	}
+	if c.Interval <= 0 {
+		return errors.New("duration should be positive")
+	}
+	if c.MinInterval < 0 || c.MaxInterval < 0 {
+		return errors.New("min-interval and max-interval should not be negative")
+	}
+	if c.RetryWindow < 0 {
+		return errors.New("retry-window should not be negative")
+	}
+	if c.RetryWindow > 0 && c.RetryQueueSize <= 0 {
+		return errors.New("retry-queue-size should be positive")
+	}
+	if c.QueueSize < 0 {
+		return errors.New("queue-size should not be negative")
+	}
+	if c.MaxNotificationsPerMinute < 0 {
+		return errors.New("max-notifications-per-minute should not be negative")
+	}
+	if c.Duplicate.Window > 0 && (c.Duplicate.Precision <= 0 || c.Duplicate.MagnitudeTolerance < 0) {
+		return errors.New("duplicate-precision should be positive and duplicate-magnitude-tolerance not negative")
+	}
+	if c.Swarm.Count < 0 {
+		return errors.New("swarm-count should not be negative")
+	}
+	if c.Swarm.Count > 0 && (c.Swarm.RadiusKm <= 0 || c.Swarm.Window <= 0) {
+		return errors.New("swarm-radius-km and swarm-window should be positive")
+	}
+	if c.Aftershock.RadiusKm < 0 {
+		return errors.New("aftershock-radius-km should not be negative")
+	}
+	if c.Aftershock.RadiusKm > 0 && c.Aftershock.Window <= 0 {
+		return errors.New("aftershock-window should be positive")
+	}
+	if c.TsunamiMagnitude < 0 {
+		return errors.New("tsunami-magnitude should not be negative")
+	}
+	if c.SWaveVelocity <= 0 {
+		return errors.New("s-wave-velocity should be positive")
+	}
+	if err := c.Filter.validate(); err != nil {
+		return err
+	}
+	tz, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return fmt.Errorf("timezone: %w", err)
+	}
+	if _, err = NewFormatter(c.MessageTemplate, c.Lang, c.MapURL, tz); err != nil {
+		return fmt.Errorf("message: %w", err)
+	}
+	if c.QuietStart != "" || c.QuietEnd != "" {
+		if _, err = parseQuietHours(c.QuietStart, c.QuietEnd, tz); err != nil {
+			return err
+		}
+	}
+	if c.History {
+		if _, _, err = c.historyRange(tz, time.Now()); err != nil {
+			return err
+		}
+		if _, ok := c.historyURL(); !ok {
+			return errors.New("history needs the chinaeew source")
+		}
+	}
+	if len(c.notifierConfigs()) == 0 && len(c.Subscriptions) == 0 && !c.History {
+		return errors.New("key should have a value when no other notifier is configured")
+	}
+	for _, n := range c.notifierConfigs() {
+		if err := n.validate(); err != nil {
+			return err
+		}
+	}
+	for i, s := range c.Subscriptions {
+		if err := s.validate(c.Filter); err != nil {
+			return fmt.Errorf("subscription %s: %w", orDefault(s.Name, strconv.Itoa(i)), err)
+		}
+	}
+	return nil
+}
+
+// validate checks the filter, except that radius-km and bbox need a location.
+func (f FilterConfig) validate() error {
+	if (f.Lat == nil) != (f.Lon == nil) {
+		return errors.New("lat and lon of your location should be set together")
+	}
+	if f.hasLocation() && (*f.Lat < -90 || *f.Lat > 90 || *f.Lon < -180 || *f.Lon > 180) {
+		return errors.New("lat should be within [-90, 90] and lon within [-180, 180]")
+	}
+	if f.RadiusKm < 0 {
+		return errors.New("radius-km should not be negative")
+	}
+	if f.RadiusKm > 0 && !f.hasLocation() {
+		return errors.New("radius-km needs both lat and lon of your location")
+	}
+	if b := f.BBox; len(b) > 0 {
+		if len(b) != 4 {
+			return errors.New("bbox should be minLat,minLon,maxLat,maxLon")
+		}
+		if b[0] < -90 || b[2] > 90 || b[0] > b[2] || b[1] < -180 || b[1] > 180 || b[3] < -180 || b[3] > 180 {
+			return errors.New("bbox should have minLat up to maxLat within [-90, 90] and lons within [-180, 180]")
+		}
+		if f.RadiusKm > 0 {
+			return errors.New("bbox and radius-km are mutually exclusive")
+		}
+	}
+	if f.MinStations < 0 {
+		return errors.New("min-stations should not be negative")
+	}
+	if f.MaxEventAge <= 0 {
+		return errors.New("max-event-age should be positive")
+	}
+	return nil
+}
+
+// barkKeys returns Key and Keys together.
+func (n NotifierConfig) barkKeys() []string {
+	var keys []string
+	if n.Key != "" {
+		keys = append(keys, n.Key)
+	}
+	return append(keys, n.Keys...)
+}
+
+func (n NotifierConfig) validate() error {
+	switch n.Type {
+	case "bark":
+		if len(n.barkKeys()) == 0 {
+			return errors.New("bark: key should have a value")
+		}
+	case "telegram":
+		if n.Token == "" || n.Chat == "" {
+			return errors.New("telegram: token and chat should have a value")
+		}
+	case "webhook":
+		if n.URL == "" {
+			return errors.New("webhook: url should have a value")
+		}
+		if _, err := parseHeaders(n.Headers); err != nil {
+			return fmt.Errorf("webhook: %w", err)
+		}
+	case "pushover":
+		if n.Token == "" || n.User == "" {
+			return errors.New("pushover: token and user should have a value")
+		}
+	case "ntfy":
+		if n.URL == "" {
+			return errors.New("ntfy: url should have a value")
+		}
+	case "dingtalk":
+		if n.URL == "" {
+			return errors.New("dingtalk: url should have a value")
+		}
+	case "wecom":
+		if n.URL == "" {
+			return errors.New("wecom: url should have a value")
+		}
+	case "feishu":
+		if n.URL == "" {
+			return errors.New("feishu: url should have a value")
+		}
+	case "serverchan":
+		if n.Key == "" {
+			return errors.New("serverchan: key should have a value")
+		}
+	case "gotify":
+		if n.URL == "" || n.Token == "" {
+			return errors.New("gotify: url and token should have a value")
+		}
+	case "email":
+		if n.Host == "" || n.From == "" || len(n.To) == 0 {
+			return errors.New("email: host, from and to should have a value")
+		}
+		if n.Port < 0 || n.Port > 65535 {
+			return fmt.Errorf("email: invalid port %d", n.Port)
+		}
+	case "discord":
+		if n.URL == "" {
+			return errors.New("discord: url should have a value")
+		}
+	case "slack":
+		if n.URL == "" {
+			return errors.New("slack: url should have a value")
+		}
+	case "mqtt":
+		if n.URL == "" || n.Topic == "" {
+			return errors.New("mqtt: url and topic should have a value")
+		}
+		if n.QoS < 0 || n.QoS > 2 {
+			return fmt.Errorf("mqtt: qos should be 0, 1 or 2, got %d", n.QoS)
+		}
+	case "nats":
+		if n.URL == "" || n.Topic == "" {
+			return errors.New("nats: url and subject should have a value")
+		}
+	case "kafka":
+		if len(n.Brokers) == 0 || n.Topic == "" {
+			return errors.New("kafka: brokers and topic should have a value")
+		}
+	case "redis":
+		if n.URL == "" || n.Topic == "" {
+			return errors.New("redis: url and channel should have a value")
+		}
+		if strings.Contains(n.URL, "://") {
+			if _, err := redis.ParseURL(n.URL); err != nil {
+				return fmt.Errorf("redis: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown notifier type %q", n.Type)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package alert
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed web/index.html
+var dashboardHTML []byte
+
+// dashboardHandler serves the page showing the recent events of /events, updated live by /events/stream.
+func dashboardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(dashboardHTML)
+}
@@ -0,0 +1,163 @@
+package alert
+
+import (
+	"math"
+	"time"
+)
+
+// seenTTL is how long the seen cache remembers an event.
+const seenTTL = time.Hour
+
+// seenCache remembers the events that have been handled so the same quake is processed only once,
+// unless the upstream has refined it with more updates since.
+type seenCache struct {
+	ttl     time.Duration
+	entries map[int]seenEntry
+}
+
+type seenEntry struct {
+	updates int
+	seenAt  time.Time
+}
+
+func newSeenCache(ttl time.Duration) *seenCache {
+	return &seenCache{ttl: ttl, entries: make(map[int]seenEntry)}
+}
+
+// seen reports whether the event has been handled with at least as many updates.
+func (c *seenCache) seen(event Event, now time.Time) bool {
+	entry, ok := c.entries[event.EventId]
+	if !ok || now.Sub(entry.seenAt) > c.ttl {
+		return false
+	}
+	return event.Updates <= entry.updates
+}
+
+func (c *seenCache) add(event Event, now time.Time) {
+	for id, entry := range c.entries {
+		if now.Sub(entry.seenAt) > c.ttl {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[event.EventId] = seenEntry{updates: event.Updates, seenAt: now}
+}
+
+// revisionCache remembers the magnitude each event was notified with, so that its updates are notified
+// again only once, when the magnitude has changed significantly.
+type revisionCache struct {
+	ttl     time.Duration
+	entries map[int]revisionEntry
+}
+
+type revisionEntry struct {
+	magnitude  float64
+	revised    bool
+	notifiedAt time.Time
+}
+
+func newRevisionCache(ttl time.Duration) *revisionCache {
+	return &revisionCache{ttl: ttl, entries: make(map[int]revisionEntry)}
+}
+
+// check reports whether the event should be notified and whether it revises an event notified before,
+// which is the case once when its magnitude has changed by more than delta.
+func (c *revisionCache) check(event Event, now time.Time, delta float64) (notify, revision bool) {
+	for id, entry := range c.entries {
+		if now.Sub(entry.notifiedAt) > c.ttl {
+			delete(c.entries, id)
+		}
+	}
+	entry, ok := c.entries[event.EventId]
+	if !ok {
+		c.entries[event.EventId] = revisionEntry{magnitude: event.Magnitude, notifiedAt: now}
+		return true, false
+	}
+	if entry.revised || math.Abs(event.Magnitude-entry.magnitude) <= delta {
+		return false, false
+	}
+	c.entries[event.EventId] = revisionEntry{magnitude: event.Magnitude, revised: true, notifiedAt: now}
+	return true, true
+}
+
+// DuplicateConfig tells when two events of different sources are the same earthquake: their epicenters
+// rounded to Precision degrees are in the same or adjacent cells, they start within Window and their
+// magnitudes differ by at most MagnitudeTolerance. A Window of 0 disables it.
+type DuplicateConfig struct {
+	Precision          float64       `yaml:"precision"`
+	Window             time.Duration `yaml:"window"`
+	MagnitudeTolerance float64       `yaml:"magnitude_tolerance"`
+}
+
+// fingerprint is what tells an earthquake apart regardless of the source reporting it.
+type fingerprint struct {
+	source string
+	// lat and lon are the indexes of the cell of the epicenter on the grid of Precision degrees.
+	lat, lon  int
+	startAt   int64
+	magnitude float64
+}
+
+func (d DuplicateConfig) fingerprint(event Event) fingerprint {
+	return fingerprint{
+		source:    event.Source,
+		lat:       int(math.Round(event.Latitude / d.Precision)),
+		lon:       int(math.Round(event.Longitude / d.Precision)),
+		startAt:   event.StartAt,
+		magnitude: event.Magnitude,
+	}
+}
+
+// duplicate reports whether the fingerprints of two sources are likely of the same earthquake, the adjacent
+// cells match too so that two close epicenters rounded on both sides of a cell boundary are not missed. The
+// events of a single source are never duplicates, as close events like aftershocks would match.
+func (d DuplicateConfig) duplicate(a, b fingerprint) bool {
+	if d.Window <= 0 || a.source == b.source {
+		return false
+	}
+	return abs(a.lat-b.lat) <= 1 && abs(a.lon-b.lon) <= 1 &&
+		time.Duration(abs(a.startAt-b.startAt))*time.Millisecond <= d.Window &&
+		math.Abs(a.magnitude-b.magnitude) <= d.MagnitudeTolerance
+}
+
+func abs[T int | int64](x T) T {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// quakeCache remembers the fingerprints of the recent events to drop an event that has already been
+// reported by another source.
+type quakeCache struct {
+	ttl     time.Duration
+	entries map[int]quakeEntry
+}
+
+type quakeEntry struct {
+	fingerprint fingerprint
+	seenAt      time.Time
+}
+
+func newQuakeCache(ttl time.Duration) *quakeCache {
+	return &quakeCache{ttl: ttl, entries: make(map[int]quakeEntry)}
+}
+
+// duplicate returns the id of a recent event other than event that is the same earthquake.
+func (c *quakeCache) duplicate(event Event, d DuplicateConfig, now time.Time) (int, bool) {
+	fp := d.fingerprint(event)
+	for id, entry := range c.entries {
+		if id != event.EventId && now.Sub(entry.seenAt) <= c.ttl && d.duplicate(fp, entry.fingerprint) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (c *quakeCache) add(event Event, d DuplicateConfig, now time.Time) {
+	for id, entry := range c.entries {
+		if now.Sub(entry.seenAt) > c.ttl {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[event.EventId] = quakeEntry{fingerprint: d.fingerprint(event), seenAt: now}
+}
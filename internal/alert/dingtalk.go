@@ -0,0 +1,91 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DingTalkNotifier posts events as markdown to the webhook of a DingTalk group robot.
+type DingTalkNotifier struct {
+	URL string
+	// Secret signs the requests when the robot has the signing security setting, empty otherwise.
+	Secret    string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+// dingTalkTooFast is the errcode of a robot sending more than 20 messages a minute, throttled for a while.
+const dingTalkTooFast = 130101
+
+// robotResponse is the response of the DingTalk and WeCom robots, a non-zero ErrCode is a failure.
+type robotResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (d *DingTalkNotifier) String() string {
+	return "dingtalk"
+}
+
+// signedURL appends the timestamp and its HMAC-SHA256 signature with the secret to the webhook url.
+func (d *DingTalkNotifier) signedURL(now time.Time) (string, error) {
+	u, err := url.Parse(d.URL)
+	if err != nil {
+		return "", err
+	}
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(d.Secret))
+	mac.Write([]byte(timestamp + "\n" + d.Secret))
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (d *DingTalkNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := d.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	webhook := d.URL
+	if d.Secret != "" {
+		if webhook, err = d.signedURL(time.Now()); err != nil {
+			return err
+		}
+	}
+	status, data, err := postJSON(ctx, d.Client, webhook, nil, map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			// markdown of DingTalk needs a blank line to break lines
+			"text": fmt.Sprintf("### %s\n\n%s", title, strings.ReplaceAll(body, "\n", "\n\n")),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var resp robotResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("dingtalk: decode response with status %d: %w", status, err)
+	}
+	if resp.ErrCode != 0 {
+		err := fmt.Errorf("dingtalk: errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+		if resp.ErrCode == dingTalkTooFast {
+			return err
+		}
+		return rejected(status, err)
+	}
+	slog.Info("notification successfully", "notifier", "dingtalk")
+	return nil
+}
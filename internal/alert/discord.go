@@ -0,0 +1,75 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	// discordMaxRetries is the number of retries of a rate limited message.
+	discordMaxRetries = 2
+	discordRetryDelay = time.Second
+)
+
+// DiscordNotifier posts events as an embed to a Discord webhook.
+type DiscordNotifier struct {
+	URL       string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+type discordRateLimit struct {
+	// RetryAfter is in seconds.
+	RetryAfter float64 `json:"retry_after"`
+}
+
+func (d *DiscordNotifier) String() string {
+	return "discord"
+}
+
+func (d *DiscordNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := d.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{
+		"embeds": []any{map[string]any{
+			"title":       title,
+			"description": body,
+			"color":       magnitudeColor(event.Magnitude),
+			"timestamp":   time.UnixMilli(event.StartAt).UTC().Format(time.RFC3339),
+			"fields": []any{
+				map[string]any{"name": "Magnitude", "value": fmt.Sprintf("%.1f", event.Magnitude), "inline": true},
+				map[string]any{"name": "Depth", "value": fmt.Sprintf("%.1f km", event.Depth), "inline": true},
+				map[string]any{"name": "Epicenter", "value": event.Epicenter, "inline": true},
+			},
+		}},
+	}
+	err = retry(ctx, discordMaxRetries, discordRetryDelay, func() error {
+		status, data, err := postJSON(ctx, d.Client, d.URL, nil, payload)
+		if status == http.StatusTooManyRequests && retryAfter(err) == 0 {
+			// without a Retry-After header, the delay is in the body
+			var limit discordRateLimit
+			if err = json.Unmarshal(data, &limit); err != nil {
+				return fmt.Errorf("discord: decode rate limit: %w", err)
+			}
+			return &rateLimitError{RetryAfter: time.Duration(limit.RetryAfter * float64(time.Second))}
+		}
+		if err != nil {
+			return err
+		}
+		if status < 200 || status > 299 {
+			return newStatusError(status, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("notification successfully", "notifier", "discord")
+	return nil
+}
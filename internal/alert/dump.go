@@ -0,0 +1,105 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dumpTimeFormat names the dumps by the UTC time of their response, sorting them chronologically.
+const dumpTimeFormat = "20060102T150405.000Z"
+
+// dumpTransport saves the body of every response of the upstream to a file of dir named by its time,
+// keeping the newest maxFiles dumps. The dumps of chinaeew can be replayed with -replay.
+type dumpTransport struct {
+	http.RoundTripper
+	dir      string
+	maxFiles int
+}
+
+func (t dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	response, err := t.RoundTripper.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(data))
+	if len(data) > 0 {
+		if err := t.dump(data, response.StatusCode, time.Now()); err != nil {
+			slog.Warn("dump response", "dir", t.dir, "err", err)
+		}
+	}
+	return response, nil
+}
+
+// dump writes data to a new file, suffixed by the status code unless it is 200, then removes the oldest dumps.
+func (t dumpTransport) dump(data []byte, status int, now time.Time) error {
+	name := now.UTC().Format(dumpTimeFormat)
+	if status != http.StatusOK {
+		name += fmt.Sprintf("-%d", status)
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, name+".json"), data, 0o644); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		return err
+	}
+	var dumps []string
+	for _, e := range entries {
+		if !e.IsDir() && isDump(e.Name()) {
+			dumps = append(dumps, e.Name())
+		}
+	}
+	slices.Sort(dumps)
+	for len(dumps) > t.maxFiles {
+		if err := os.Remove(filepath.Join(t.dir, dumps[0])); err != nil {
+			return err
+		}
+		dumps = dumps[1:]
+	}
+	return nil
+}
+
+// isDump reports whether name is the name of a dump, so that the other files of the directory, like a state
+// file, are never removed.
+func isDump(name string) bool {
+	name, ok := strings.CutSuffix(name, ".json")
+	if !ok {
+		return false
+	}
+	if i := strings.LastIndexByte(name, '-'); i >= 0 {
+		if _, err := strconv.Atoi(name[i+1:]); err != nil {
+			return false
+		}
+		name = name[:i]
+	}
+	_, err := time.Parse(dumpTimeFormat, name)
+	return err == nil
+}
+
+// withDumps returns a copy of client saving the responses to dir, see dumpTransport.
+func withDumps(client *http.Client, dir string, maxFiles int) (*http.Client, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c := *client
+	c.Transport = dumpTransport{RoundTripper: transport, dir: dir, maxFiles: maxFiles}
+	return &c, nil
+}
@@ -0,0 +1,127 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSMTPPort is the submission port, upgraded to TLS with STARTTLS.
+const defaultSMTPPort = 587
+
+// smtpsPort is the port of SMTP over implicit TLS.
+const smtpsPort = 465
+
+// EmailNotifier mails events through an SMTP server.
+type EmailNotifier struct {
+	Host string
+	// Port 465 is dialed with TLS, the others are upgraded with STARTTLS when the server offers it.
+	Port int
+	// Username and Password authenticate with PLAIN, no authentication when Username is empty.
+	Username  string
+	Password  string
+	From      string
+	To        []string
+	Formatter *Formatter
+}
+
+func (e *EmailNotifier) String() string {
+	return "email"
+}
+
+func (e *EmailNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := e.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	msg := e.message(title, body, time.Now())
+
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	tlsConfig := &tls.Config{ServerName: e.Host}
+	var conn net.Conn
+	if e.Port == smtpsPort {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	// net/smtp has no context, the deadline bounds the whole conversation instead
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else {
+		_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+	c, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() {
+		_ = c.Close()
+	}()
+
+	if ok, _ := c.Extension("STARTTLS"); ok && e.Port != smtpsPort {
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if e.Username != "" {
+		if err = c.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err = c.Mail(e.From); err != nil {
+		return err
+	}
+	for _, to := range e.To {
+		if err = c.Rcpt(to); err != nil {
+			return fmt.Errorf("rcpt %s: %w", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = c.Quit(); err != nil {
+		return err
+	}
+	slog.Info("notification successfully", "notifier", "email")
+	return nil
+}
+
+// message builds a plain text mail, base64 encoded since the messages are usually not ASCII.
+func (e *EmailNotifier) message(title, body string, now time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", e.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("utf-8", title))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString([]byte(title + "\n" + body))
+	// RFC 2045 limits the encoded lines to 76 characters
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.Bytes()
+}
@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// probeTimeout bounds a probe of the failing primary, so that it delays the polls of the healthy source little.
+const probeTimeout = 5 * time.Second
+
+// FailoverSource polls the first of Sources, the primary, and fails over to the next one after FailureThreshold
+// consecutive failures of the active source. While failed over, the primary is probed every ProbeInterval after
+// the poll of the active source, and polled again from the next poll once a probe succeeds. The events are tagged
+// with the name of their source so that the loop drops the events reported again by another source, see
+// DuplicateConfig.
+type FailoverSource struct {
+	Sources []Source
+	// Names of the sources, for the logs.
+	Names            []string
+	FailureThreshold int
+	ProbeInterval    time.Duration
+
+	active    int
+	failures  int
+	lastProbe time.Time
+	// polled is the source of the last poll.
+	polled int
+}
+
+func (f *FailoverSource) late() bool {
+	return publishesLate(f.Sources[f.polled])
+}
+
+// prober is implemented by the sources that can be probed with a single request, without the retries of Poll.
+type prober interface {
+	probe(ctx context.Context, since int64) error
+}
+
+func (f *FailoverSource) Poll(ctx context.Context, since int64) ([]Event, error) {
+	now := time.Now()
+	active := f.active
+	f.polled = active
+	events, err := f.Sources[active].Poll(ctx, since)
+	if active > 0 && now.Sub(f.lastProbe) >= f.ProbeInterval && ctx.Err() == nil {
+		f.lastProbe = now
+		f.probe(ctx, since)
+	}
+	if errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err != nil {
+		if active != f.active {
+			// failed back by the probe
+			return nil, err
+		}
+		f.failures++
+		if f.failures >= f.FailureThreshold && f.active < len(f.Sources)-1 {
+			slog.Warn("source failing, failing over", "source", f.Names[f.active], "failures", f.failures,
+				"to", f.Names[f.active+1], "err", err)
+			f.active, f.failures = f.active+1, 0
+			f.lastProbe = now
+		}
+		return nil, err
+	}
+	if active == f.active {
+		f.failures = 0
+	}
+	return tagSource(events, f.Names[active]), nil
+}
+
+// probe checks the primary with a single request bounded by probeTimeout, failing back to it when it succeeds.
+func (f *FailoverSource) probe(ctx context.Context, since int64) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	var err error
+	if p, ok := f.Sources[0].(prober); ok {
+		err = p.probe(ctx, since)
+	} else {
+		_, err = f.Sources[0].Poll(ctx, since)
+	}
+	if err != nil {
+		slog.Debug("primary source still failing", "source", f.Names[0], "err", err)
+		return
+	}
+	slog.Info("primary source is back, failing back", "source", f.Names[0], "from", f.Names[f.active])
+	f.active, f.failures = 0, 0
+}
+
+func tagSource(events []Event, name string) []Event {
+	for i := range events {
+		events[i].Source = name
+	}
+	return events
+}
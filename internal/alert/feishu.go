@@ -0,0 +1,97 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// FeishuNotifier posts events as an interactive card to the webhook of a Feishu (Lark) custom bot.
+type FeishuNotifier struct {
+	URL string
+	// Secret signs the requests when the bot has the signature verification setting, empty otherwise.
+	Secret    string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+// feishuTooFast is the code of a bot sending more than its rate limit.
+const feishuTooFast = 11232
+
+type feishuResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (f *FeishuNotifier) String() string {
+	return "feishu"
+}
+
+// feishuSign signs the timestamp in seconds, Feishu uses the string to sign as the HMAC-SHA256 key of an empty message.
+func feishuSign(timestamp, secret string) string {
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// feishuTemplate is the color of the card header by magnitude.
+func feishuTemplate(magnitude float64) string {
+	switch {
+	case magnitude >= 6:
+		return "red"
+	case magnitude >= 4.5:
+		return "orange"
+	default:
+		return "yellow"
+	}
+}
+
+func (f *FeishuNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := f.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{
+		"msg_type": "interactive",
+		"card": map[string]any{
+			"header": map[string]any{
+				"title":    map[string]string{"tag": "plain_text", "content": title},
+				"template": feishuTemplate(event.Magnitude),
+			},
+			"elements": []any{
+				map[string]any{"tag": "div", "text": map[string]string{
+					"tag":     "lark_md",
+					"content": fmt.Sprintf("**M%.1f %s**\n%s", event.Magnitude, event.Epicenter, body),
+				}},
+			},
+		},
+	}
+	if f.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		payload["timestamp"] = timestamp
+		payload["sign"] = feishuSign(timestamp, f.Secret)
+	}
+	status, data, err := postJSON(ctx, f.Client, f.URL, nil, payload)
+	if err != nil {
+		return err
+	}
+	var resp feishuResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("feishu: decode response with status %d: %w", status, err)
+	}
+	if resp.Code != 0 {
+		err := fmt.Errorf("feishu: code %d: %s", resp.Code, resp.Msg)
+		if resp.Code == feishuTooFast {
+			return err
+		}
+		return rejected(status, err)
+	}
+	slog.Info("notification successfully", "notifier", "feishu")
+	return nil
+}
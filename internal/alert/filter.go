@@ -0,0 +1,63 @@
+package alert
+
+import (
+	"strings"
+	"time"
+)
+
+// rejectReason reports why the event should not be notified at now, or "" when it passes every filter.
+func (f FilterConfig) rejectReason(event Event, now time.Time) string {
+	switch {
+	case now.Sub(time.UnixMilli(event.StartAt)) > f.MaxEventAge:
+		return "out of date"
+	case event.Magnitude < f.MinMagnitude && event.Swarm == 0:
+		return "below the minimum magnitude"
+	case f.RadiusKm > 0 && event.Local != nil && event.Local.Distance > f.RadiusKm:
+		return "out of the radius"
+	case len(f.BBox) == 4 && !inBBox(event.Latitude, event.Longitude, f.BBox):
+		return "out of the bounding box"
+	case !f.inRegions(event.Epicenter):
+		return "out of the regions"
+	case f.InsideNetOnly && event.InsideNet == 0:
+		return "outside the monitoring network"
+	case event.Sations < f.MinStations:
+		return "reported by too few stations"
+	}
+	return ""
+}
+
+// hasLocation reports whether both coordinates of the location are configured.
+func (f FilterConfig) hasLocation() bool {
+	return f.Lat != nil && f.Lon != nil
+}
+
+// location returns the coordinates of the location, which must be configured.
+func (f FilterConfig) location() (lat, lon float64) {
+	return *f.Lat, *f.Lon
+}
+
+// inRegions reports whether epicenter contains one of the regions, always true without regions.
+func (f FilterConfig) inRegions(epicenter string) bool {
+	if len(f.Regions) == 0 {
+		return true
+	}
+	epicenter = strings.ToLower(epicenter)
+	for _, region := range f.Regions {
+		if strings.Contains(epicenter, strings.ToLower(region)) {
+			return true
+		}
+	}
+	return false
+}
+
+// inBBox reports whether the coordinate is inside box, minLat,minLon,maxLat,maxLon with the bounds included.
+// The box crosses the antimeridian when minLon is above maxLon.
+func inBBox(lat, lon float64, box []float64) bool {
+	if lat < box[0] || lat > box[2] {
+		return false
+	}
+	if box[1] <= box[3] {
+		return box[1] <= lon && lon <= box[3]
+	}
+	return lon >= box[1] || lon <= box[3]
+}
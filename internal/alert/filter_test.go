@@ -0,0 +1,43 @@
+package alert
+
+import "testing"
+
+func TestInBBox(t *testing.T) {
+	// mainland China, and the western Pacific across the antimeridian
+	china := []float64{18, 73, 54, 135}
+	pacific := []float64{-50, 160, 10, -150}
+	tests := []struct {
+		name     string
+		lat, lon float64
+		box      []float64
+		want     bool
+	}{
+		{"inside", 30.6, 104.1, china, true},
+		{"north of the box", 60, 104.1, china, false},
+		{"south of the box", 10, 104.1, china, false},
+		{"west of the box", 30.6, 60, china, false},
+		{"east of the box", 30.6, 140, china, false},
+		{"on the min corner", 18, 73, china, true},
+		{"on the max corner", 54, 135, china, true},
+		{"on the north edge", 54, 100, china, true},
+		{"on the west edge", 30, 73, china, true},
+		{"just outside the edge", 54.01, 100, china, false},
+		{"crossing: west of the antimeridian", -20, 175, pacific, true},
+		{"crossing: east of the antimeridian", -20, -170, pacific, true},
+		{"crossing: on the antimeridian", -20, 180, pacific, true},
+		{"crossing: on the antimeridian at -180", -20, -180, pacific, true},
+		{"crossing: on the min longitude", -20, 160, pacific, true},
+		{"crossing: on the max longitude", -20, -150, pacific, true},
+		{"crossing: in the gap between the edges", -20, 0, pacific, false},
+		{"crossing: just west of the box", -20, 159.9, pacific, false},
+		{"crossing: just east of the box", -20, -149.9, pacific, false},
+		{"crossing: out of the latitudes", 20, 175, pacific, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inBBox(tt.lat, tt.lon, tt.box); got != tt.want {
+				t.Errorf("inBBox(%.2f, %.2f, %v) = %t, want %t", tt.lat, tt.lon, tt.box, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package alert
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+// distanceKm returns the great-circle distance between two coordinates using the haversine formula.
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Attenuation holds the coefficients of the intensity attenuation relationship
+// I = A + B*M - C*lg(R+D), with M the magnitude and R the epicentral distance in kilometers.
+type Attenuation struct {
+	A float64 `yaml:"a"`
+	B float64 `yaml:"b"`
+	C float64 `yaml:"c"`
+	D float64 `yaml:"d"`
+}
+
+// defaultAttenuation is the relationship fitted for western China (汪素云 et al.).
+var defaultAttenuation = Attenuation{A: 5.253, B: 1.398, C: 4.164, D: 26}
+
+// intensity estimates the seismic intensity on the Chinese scale, bounded to [0, 12].
+func (a Attenuation) intensity(magnitude, distance float64) float64 {
+	i := a.A + a.B*magnitude - a.C*math.Log10(distance+a.D)
+	return math.Max(0, math.Min(12, i))
+}
+
+// locate estimates how the event reaches the location at lat, lon, with the S-wave
+// travelling the hypocentral distance at sWaveVelocity km/s.
+func locate(event Event, lat, lon, sWaveVelocity float64, attenuation Attenuation) *Local {
+	d := distanceKm(lat, lon, event.Latitude, event.Longitude)
+	travel := math.Hypot(d, event.Depth) / sWaveVelocity
+	return &Local{
+		Distance:     d,
+		SWaveArrival: event.StartAt + int64(travel*1000),
+		Intensity:    attenuation.intensity(event.Magnitude, d),
+	}
+}
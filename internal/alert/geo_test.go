@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		// want is the published great-circle distance, matched within 1%
+		want float64
+	}{
+		{"Beijing to Shanghai", 39.9042, 116.4074, 31.2304, 121.4737, 1067},
+		{"Chengdu to Chongqing", 30.5728, 104.0668, 29.5630, 106.5516, 264},
+		{"London to Paris", 51.5074, -0.1278, 48.8566, 2.3522, 344},
+		{"across the antimeridian", 0, 179.5, 0, -179.5, 111.2},
+		{"antipodes", 0, 0, 0, 180, math.Pi * earthRadiusKm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := distanceKm(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > tt.want/100 {
+				t.Errorf("distanceKm = %.1f km, want %.1f km", got, tt.want)
+			}
+			if back := distanceKm(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(back-got) > 1e-9 {
+				t.Errorf("distanceKm is not symmetric: %.6f km and %.6f km", got, back)
+			}
+		})
+	}
+	if got := distanceKm(30.5728, 104.0668, 30.5728, 104.0668); got != 0 {
+		t.Errorf("distanceKm of the same point = %f km, want 0", got)
+	}
+}
+
+func TestIntensity(t *testing.T) {
+	tests := []struct {
+		name                string
+		magnitude, distance float64
+		want                float64
+	}{
+		// I = 5.253 + 1.398*M - 4.164*lg(R+26)
+		{"strong nearby", 7.0, 100, 5.253 + 1.398*7.0 - 4.164*math.Log10(126)},
+		{"moderate at the epicenter", 5.0, 0, 5.253 + 1.398*5.0 - 4.164*math.Log10(26)},
+		{"moderate afar", 5.0, 50, 5.253 + 1.398*5.0 - 4.164*math.Log10(76)},
+		{"too weak to be felt is clamped to 0", 3.0, 1000, 0},
+		{"beyond the scale is clamped to 12", 10.0, 0, 12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultAttenuation.intensity(tt.magnitude, tt.distance); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("intensity(M%.1f, %.0f km) = %.3f, want %.3f", tt.magnitude, tt.distance, got, tt.want)
+			}
+		})
+	}
+	// the shaking weakens with the distance
+	if near, far := defaultAttenuation.intensity(6, 10), defaultAttenuation.intensity(6, 200); near <= far {
+		t.Errorf("intensity at 10 km = %.2f, not above %.2f at 200 km", near, far)
+	}
+}
+
+func TestFormatIntensity(t *testing.T) {
+	formatter, err := NewFormatter("", "zh", "", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		intensity float64
+		want      string
+	}{
+		{6.29, "预计本地烈度:6度"},
+		{6.49, "预计本地烈度:6度"},
+		{6.51, "预计本地烈度:7度"},
+		{0, "预计本地烈度:0度"},
+		{12, "预计本地烈度:12度"},
+	}
+	for _, tt := range tests {
+		_, body, err := formatter.format(Event{Magnitude: 6, Local: &Local{Distance: 80, Intensity: tt.intensity}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(body, tt.want) {
+			t.Errorf("intensity %.2f is formatted as %q, want %q", tt.intensity, body, tt.want)
+		}
+	}
+}
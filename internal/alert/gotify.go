@@ -0,0 +1,60 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// GotifyNotifier sends events to a self-hosted Gotify server with the token of an application.
+type GotifyNotifier struct {
+	URL       string
+	Token     string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+func (g *GotifyNotifier) String() string {
+	return "gotify"
+}
+
+// gotifyPriority maps the magnitude to a Gotify priority from 0 to 10, the clients alert loudly from 8.
+func gotifyPriority(magnitude float64) int {
+	switch {
+	case magnitude >= 6:
+		return 10
+	case magnitude >= 5:
+		return 8
+	case magnitude >= 4:
+		return 5
+	default:
+		return 2
+	}
+}
+
+func (g *GotifyNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := g.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	priority := gotifyPriority(event.Magnitude)
+	if event.Tsunami {
+		priority = 10
+	}
+	header := http.Header{"X-Gotify-Key": {g.Token}}
+	status, data, err := postJSON(ctx, g.Client, strings.TrimSuffix(g.URL, "/")+"/message", header, map[string]any{
+		"title":    title,
+		"message":  body,
+		"priority": priority,
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("gotify: %w", newStatusError(status, data))
+	}
+	slog.Info("notification successfully", "notifier", "gotify")
+	return nil
+}
@@ -0,0 +1,36 @@
+package alert
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// lastQuerySuccess is the unix nano time of the last successful upstream query, 0 before the first one.
+var lastQuerySuccess atomic.Int64
+
+func healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// readyzHandler reports ready once a query has succeeded within the staleness window.
+func readyzHandler(staleness time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		last := lastQuerySuccess.Load()
+		if last == 0 {
+			http.Error(w, "no successful query yet", http.StatusServiceUnavailable)
+			return
+		}
+		if circuitState.Load() == circuitOpen {
+			http.Error(w, "circuit open, the upstream is failing", http.StatusServiceUnavailable)
+			return
+		}
+		if since := time.Since(time.Unix(0, last)); since > staleness {
+			http.Error(w, "no successful query in "+since.Truncate(time.Second).String(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
@@ -0,0 +1,136 @@
+package alert
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// historyLayouts are the layouts accepted by HistoryStart and HistoryEnd, besides RFC 3339.
+var historyLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// historyRange parses HistoryStart and HistoryEnd in tz, the end defaulting to now.
+func (c *Config) historyRange(tz *time.Location, now time.Time) (start, end time.Time, err error) {
+	if c.HistoryStart == "" {
+		return start, end, errors.New("history-start should have a value")
+	}
+	if start, err = parseHistoryTime(c.HistoryStart, tz); err != nil {
+		return start, end, fmt.Errorf("history-start: %w", err)
+	}
+	end = now
+	if c.HistoryEnd != "" {
+		if end, err = parseHistoryTime(c.HistoryEnd, tz); err != nil {
+			return start, end, fmt.Errorf("history-end: %w", err)
+		}
+	}
+	if !start.Before(end) {
+		return start, end, errors.New("history-start should be before history-end")
+	}
+	return start, end, nil
+}
+
+func parseHistoryTime(value string, tz *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range historyLayouts {
+		if t, err := time.ParseInLocation(layout, value, tz); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a time like 2006-01-02 15:04 or %s", value, time.RFC3339)
+}
+
+// historyURL is the url of the first chinaeew source, the only feed taking a start_at parameter.
+func (c *Config) historyURL() (string, bool) {
+	for _, s := range c.sourceSpecs() {
+		if s.name == "chinaeew" {
+			return orDefault(s.url, chinaEEWURL), true
+		}
+	}
+	return "", false
+}
+
+// History queries the events that started between cfg.HistoryStart and cfg.HistoryEnd with a single
+// query, without polling or notifying, and writes them to w as CSV, oldest first. They are saved to
+// cfg.DB too when set, to seed the store.
+func History(ctx context.Context, cfg Config, w io.Writer) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	tz, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return err
+	}
+	start, end, err := cfg.historyRange(tz, time.Now())
+	if err != nil {
+		return err
+	}
+	client := cfg.QueryClient
+	if client == nil {
+		if client, err = newHTTPClient(cfg.QueryHTTP, cfg.UserAgent); err != nil {
+			return err
+		}
+	}
+	base, _ := cfg.historyURL()
+	// every revision since start, as the newest one is unknown
+	url := fmt.Sprintf("%s?start_at=%d&updates=%d", base, start.UnixMilli(), max(cfg.Updates, 0))
+	resp, err := query[Response](ctx, client, url, nil, cfg.MaxRetries, cfg.RetryBaseDelay)
+	if err != nil {
+		return err
+	}
+	if resp.Code != codeSuccess {
+		return fmt.Errorf("code %d: %s", resp.Code, resp.Message)
+	}
+	events := slices.DeleteFunc(resp.Data, func(e Event) bool {
+		return e.StartAt < start.UnixMilli() || e.StartAt >= end.UnixMilli()
+	})
+	events = latestRevisions(events)
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return cmp.Compare(a.StartAt, b.StartAt)
+	})
+	slog.Info("found the history", "num", len(events), "start", start, "end", end)
+
+	if cfg.DB != "" {
+		db, err := OpenSQLiteStore(cfg.DB)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		for _, event := range events {
+			if err = db.Save(event); err != nil {
+				return err
+			}
+		}
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write(csvHeader)
+	for _, event := range events {
+		_ = cw.Write(csvRecord(event))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// latestRevisions keeps the newest revision of each event, as updates=0 may return several of them.
+func latestRevisions(events []Event) []Event {
+	index := make(map[int]int, len(events))
+	var latest []Event
+	for _, e := range events {
+		i, ok := index[e.EventId]
+		switch {
+		case !ok:
+			index[e.EventId] = len(latest)
+			latest = append(latest, e)
+		case e.Updates > latest[i].Updates:
+			latest[i] = e
+		}
+	}
+	return latest
+}
@@ -0,0 +1,80 @@
+package alert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// newHTTPClient builds a client of outgoing requests, verifying certificates unless h.Insecure is set.
+// When h.CACert is given, the PEM bundle replaces the system roots. Requests go through h.Proxy,
+// http, https or socks5, when given, or the proxy of the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment.
+// Every request is sent with userAgent, or defaultUserAgent when empty, and must complete within h.Timeout.
+func newHTTPClient(h HTTPConfig, userAgent string) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: h.Insecure,
+	}
+	if h.CACert != "" {
+		pem, err := os.ReadFile(h.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", h.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	proxyFunc := http.ProxyFromEnvironment
+	if h.Proxy != "" {
+		u, err := parseProxy(h.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxyFunc = http.ProxyURL(u)
+	}
+	return &http.Client{
+		Transport: &userAgentTransport{
+			base: &http.Transport{
+				Proxy:           proxyFunc,
+				TLSClientConfig: tlsConfig,
+			},
+			userAgent: orDefault(userAgent, defaultUserAgent()),
+		},
+		Timeout: h.Timeout,
+	}, nil
+}
+
+func parseProxy(proxy string) (*url.URL, error) {
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy: unsupported scheme %q, should be http, https or socks5", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy: no host in %q", proxy)
+	}
+	return u, nil
+}
+
+// userAgentTransport sets the User-Agent of the requests that do not have one.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		// a RoundTripper should not modify the request
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
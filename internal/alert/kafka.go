@@ -0,0 +1,71 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaNotifier produces a message per event to a Kafka topic, keyed by EventId with the event as JSON value.
+type KafkaNotifier struct {
+	// Brokers are the host:port addresses of the bootstrap brokers.
+	Brokers []string
+	Topic   string
+
+	mu     sync.Mutex
+	writer *kafka.Writer
+}
+
+func (k *KafkaNotifier) String() string {
+	return "kafka"
+}
+
+// producer creates the writer on the first notification, it connects to the brokers as needed.
+func (k *KafkaNotifier) producer() *kafka.Writer {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.writer == nil {
+		k.writer = &kafka.Writer{
+			Addr:  kafka.TCP(k.Brokers...),
+			Topic: k.Topic,
+			// the same event, and its updates, always goes to the same partition
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		}
+	}
+	return k.writer
+}
+
+func (k *KafkaNotifier) Send(ctx context.Context, event Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	err = k.producer().WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strconv.Itoa(event.EventId)),
+		Value: value,
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("notification successfully", "notifier", "kafka")
+	return nil
+}
+
+// Close flushes the pending messages and closes the connections to the brokers.
+func (k *KafkaNotifier) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.writer == nil {
+		return nil
+	}
+	err := k.writer.Close()
+	k.writer = nil
+	return err
+}
@@ -0,0 +1,117 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// messageTemplates are the built-in templates by language, used when no template is configured.
+var messageTemplates = map[string]string{
+	"zh": zhMessageTemplate,
+	"en": enMessageTemplate,
+}
+
+const zhMessageTemplate = `{{if .Swarm}}地震群({{.Swarm}}次) {{end}}{{if .Tsunami}}⚠️ 强震/可能海啸 {{end}}{{if .Revision}}(更新){{end}}{{.Time}} 有{{printf "%.1f" .Magnitude}}级地震发生了({{.Severity.In "zh"}}){{with .Mainshock}},疑似M{{printf "%.1f" .Magnitude}}地震的余震{{end}}
+地点:{{.Epicenter}},东经:{{printf "%.1f" .Longitude}}°,北纬:{{printf "%.1f" .Latitude}}°,地震深度:{{printf "%.1f" .Depth}}公里
+{{- with .Local}},距离:{{printf "%.1f" .Distance}}公里,预计本地烈度:{{printf "%.0f" .Intensity}}度
+{{- if gt $.SWaveCountdown 0}},预计S波到达剩余 {{$.SWaveCountdown}} 秒{{else}},S波预计已到达{{end}}
+{{- end}}
+{{- with .MapURL}}
+地图:{{.}}{{end}}`
+
+const enMessageTemplate = `{{if .Swarm}}Swarm of {{.Swarm}} quakes: {{end}}{{if .Tsunami}}⚠️ Strong quake/possible tsunami {{end}}{{if .Revision}}(updated) {{end}}M{{printf "%.1f" .Magnitude}} {{.Severity}} earthquake near {{.Epicenter}} at {{.Time}} (depth {{printf "%.1f" .Depth}} km){{with .Mainshock}}, possible aftershock of M{{printf "%.1f" .Magnitude}}{{end}}
+Location: {{printf "%.1f" .Longitude}}°E, {{printf "%.1f" .Latitude}}°N
+{{- with .Local}}, distance: {{printf "%.1f" .Distance}} km, estimated local intensity: {{printf "%.0f" .Intensity}}
+{{- if gt $.SWaveCountdown 0}}, S-wave arrives in {{$.SWaveCountdown}} s{{else}}, S-wave has likely arrived{{end}}
+{{- end}}
+{{- with .MapURL}}
+Map: {{.}}{{end}}`
+
+// DefaultMapURL is the template of the map link of an event, at its epicenter on OpenStreetMap.
+const DefaultMapURL = "https://www.openstreetmap.org/?mlat={{.Latitude}}&mlon={{.Longitude}}#map=8/{{.Latitude}}/{{.Longitude}}"
+
+// Formatter renders events into notification messages with a text/template,
+// the first line of the output is the title and the rest is the body.
+type Formatter struct {
+	tmpl *template.Template
+	// mapTmpl renders the map link of an event, nil for no link.
+	mapTmpl *template.Template
+	tz      *time.Location
+}
+
+// messageData is what the message template is executed with.
+type messageData struct {
+	Event
+	// Time is the local time the event started at.
+	Time string
+	// Severity is the classification of the event, see classifySeverity.
+	Severity Severity
+	// MapURL is the link to the epicenter on a map, empty when no map is configured.
+	MapURL string
+	// SWaveCountdown is the seconds left before the S-wave arrives at the configured location, 0 once it has arrived.
+	SWaveCountdown int
+}
+
+// NewFormatter parses text as the message template, an empty text selects the built-in template of lang.
+// mapURL is the template of the map link executed with the event, empty for no link. Times are shown in tz.
+func NewFormatter(text, lang, mapURL string, tz *time.Location) (*Formatter, error) {
+	if text == "" {
+		var ok bool
+		if text, ok = messageTemplates[lang]; !ok {
+			return nil, fmt.Errorf("unsupported language %q", lang)
+		}
+	}
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	f := &Formatter{tmpl: tmpl, tz: tz}
+	if mapURL != "" {
+		if f.mapTmpl, err = template.New("map").Parse(mapURL); err != nil {
+			return nil, fmt.Errorf("map url: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// mapURL returns the map link of the event, empty when no map is configured.
+func (f *Formatter) mapURL(event Event) (string, error) {
+	if f.mapTmpl == nil {
+		return "", nil
+	}
+	var sb strings.Builder
+	if err := f.mapTmpl.Execute(&sb, event); err != nil {
+		return "", fmt.Errorf("map url: %w", err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func (f *Formatter) format(event Event) (title, body string, err error) {
+	data := messageData{
+		Event:    event,
+		Time:     time.UnixMilli(event.StartAt).In(f.tz).Format(time.DateTime),
+		Severity: classifySeverity(event),
+	}
+	if data.MapURL, err = f.mapURL(event); err != nil {
+		return "", "", err
+	}
+	if event.Local != nil {
+		if remaining := time.Until(time.UnixMilli(event.Local.SWaveArrival)); remaining > 0 {
+			data.SWaveCountdown = int(remaining.Seconds())
+		}
+	}
+	var sb strings.Builder
+	if err = f.tmpl.Execute(&sb, data); err != nil {
+		return "", "", err
+	}
+	title, body, _ = strings.Cut(sb.String(), "\n")
+	return strings.TrimSpace(title), strings.TrimSpace(body), nil
+}
+
+var markdownReplacer = strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+
+func escapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
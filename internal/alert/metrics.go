@@ -0,0 +1,69 @@
+package alert
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	eventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "earthquake_events_total",
+		Help: "The number of new events found from the upstream, by severity.",
+	}, []string{"severity"})
+	notificationsSentTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "earthquake_notifications_sent_total",
+		Help: "The number of notifications sent successfully.",
+	}, []string{"notifier"})
+	notificationErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "earthquake_notification_errors_total",
+		Help: "The number of notifications failed to send.",
+	}, []string{"notifier"})
+	queryErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "earthquake_query_errors_total",
+		Help: "The number of failed queries to the upstream.",
+	})
+	queryDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "earthquake_query_duration_seconds",
+		Help:    "The duration of queries to the upstream, including retries.",
+		Buckets: prometheus.DefBuckets,
+	})
+	upstreamRequestDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "earthquake_upstream_request_duration_seconds",
+		Help:    "The latency of each request to the upstream until its response headers.",
+		Buckets: prometheus.DefBuckets,
+	})
+	upstreamRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "earthquake_upstream_requests_total",
+		Help: "The number of requests to the upstream, by result, success or failure.",
+	}, []string{"result"})
+	circuitStateGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "earthquake_circuit_state",
+		Help: "The state of the circuit breaker of the upstream, 0 closed, 1 open and 2 half-open.",
+	})
+	newestEventAge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "earthquake_newest_event_age_seconds",
+		Help: "The age of the newest event from the upstream at the last successful query.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(eventsTotal, notificationsSentTotal, notificationErrorsTotal, queryErrorsTotal, queryDuration,
+		upstreamRequestDuration, upstreamRequestsTotal, circuitStateGauge, newestEventAge)
+}
+
+// counterTotal sums the values of the counters collected from c since start.
+func counterTotal(c prometheus.Collector) float64 {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	var total float64
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err == nil && metric.Counter != nil {
+			total += metric.Counter.GetValue()
+		}
+	}
+	return total
+}
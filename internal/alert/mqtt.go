@@ -0,0 +1,121 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MQTTNotifier publishes the events as JSON to a topic of an MQTT broker, for home automation.
+type MQTTNotifier struct {
+	// Broker is the url of the broker like tcp://host:1883 or ssl://host:8883.
+	Broker   string
+	Topic    string
+	Username string
+	Password string
+	// ClientID identifies the client to the broker, which disconnects the older of two clients with the same
+	// ID, a random one when empty.
+	ClientID string
+	// QoS is the MQTT quality of service of the messages, 0, 1 or 2.
+	QoS byte
+	// Timeout bounds the connecting and every publish, mqttTimeout when 0.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	client mqtt.Client
+}
+
+// mqttTimeout bounds the wait for the broker when the notifier has no Timeout, a QoS 1 or 2 publish
+// would otherwise wait for as long as the client is reconnecting.
+const mqttTimeout = 10 * time.Second
+
+func (m *MQTTNotifier) timeout() time.Duration {
+	if m.Timeout > 0 {
+		return m.Timeout
+	}
+	return mqttTimeout
+}
+
+func (m *MQTTNotifier) String() string {
+	return "mqtt"
+}
+
+// connect connects to the broker on the first notification, the client reconnects on its own afterwards.
+func (m *MQTTNotifier) connect(ctx context.Context) (mqtt.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.client != nil {
+		return m.client, nil
+	}
+	clientID := m.ClientID
+	if clientID == "" {
+		// unlike the pid, which is 1 in every container, unique across the instances and their notifiers
+		clientID = fmt.Sprintf("earthquake-alert-%016x", rand.Uint64())
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(m.Broker).
+		SetClientID(clientID).
+		SetUsername(m.Username).
+		SetPassword(m.Password).
+		SetAutoReconnect(true).
+		SetMaxReconnectInterval(time.Minute).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			slog.Warn("mqtt connection lost, reconnecting", "broker", m.Broker, "err", err)
+		})
+	client := mqtt.NewClient(opts)
+	if err := wait(ctx, client.Connect(), m.timeout()); err != nil {
+		// stop the connecting, which would otherwise go on in the background
+		client.Disconnect(0)
+		return nil, fmt.Errorf("connect %s: %w", m.Broker, err)
+	}
+	m.client = client
+	return client, nil
+}
+
+// wait waits for token to complete, ctx to be done or timeout to elapse.
+func wait(ctx context.Context, token mqtt.Token, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// mqttDisconnectQuiesce is the time the pending messages are given to be sent when disconnecting, in milliseconds.
+const mqttDisconnectQuiesce = 1000
+
+// Close disconnects from the broker.
+func (m *MQTTNotifier) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.client != nil {
+		m.client.Disconnect(mqttDisconnectQuiesce)
+		m.client = nil
+	}
+	return nil
+}
+
+func (m *MQTTNotifier) Send(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	client, err := m.connect(ctx)
+	if err != nil {
+		return err
+	}
+	if err = wait(ctx, client.Publish(m.Topic, m.QoS, false, payload), m.timeout()); err != nil {
+		return err
+	}
+	slog.Info("notification successfully", "notifier", "mqtt")
+	return nil
+}
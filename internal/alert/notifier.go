@@ -0,0 +1,336 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Notifier delivers an event to a notification backend.
+type Notifier interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// MultiNotifier fans an event out to every notifier concurrently.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Send(ctx context.Context, event Event) error {
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(m))
+	)
+	for i, n := range m {
+		wg.Add(1)
+		go func(i int, n Notifier) {
+			defer wg.Done()
+			name := notifierName(n)
+			if g, ok := n.(gate); ok && !g.accept(event) {
+				slog.Debug("skip the notification", "notifier", name, "event", event)
+				return
+			}
+			ctx, span := tracer.Start(ctx, "send", trace.WithAttributes(attribute.String("notifier", name)))
+			defer span.End()
+			err := recovered("notifier "+name, func() error {
+				return n.Send(ctx, event)
+			})
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				notificationErrorsTotal.WithLabelValues(name).Inc()
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+				return
+			}
+			notificationsSentTotal.WithLabelValues(name).Inc()
+		}(i, n)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Close closes the notifiers holding a connection, once nothing is sent anymore.
+func (m MultiNotifier) Close() error {
+	var errs []error
+	for _, n := range m {
+		n = unwrapAll(n)
+		if c, ok := n.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", notifierName(n), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// wrapper is implemented by the notifiers adding a behavior to another one.
+type wrapper interface {
+	unwrap() Notifier
+}
+
+// unwrapAll returns the notifier at the bottom of the wrappers of n.
+func unwrapAll(n Notifier) Notifier {
+	for w, ok := n.(wrapper); ok; w, ok = n.(wrapper) {
+		n = w.unwrap()
+	}
+	return n
+}
+
+// gate is implemented by the notifiers that only want some events, MultiNotifier skips the others.
+type gate interface {
+	accept(event Event) bool
+}
+
+// minMagnitudeNotifier only sends the events of at least minMagnitude to the wrapped notifier.
+type minMagnitudeNotifier struct {
+	Notifier
+	minMagnitude float64
+}
+
+func (m minMagnitudeNotifier) String() string {
+	return notifierName(m.Notifier)
+}
+
+func (m minMagnitudeNotifier) unwrap() Notifier {
+	return m.Notifier
+}
+
+func (m minMagnitudeNotifier) accept(event Event) bool {
+	return event.Magnitude >= m.minMagnitude
+}
+
+// dryRunNotifier logs the message the wrapped notifier would have sent instead of sending it.
+type dryRunNotifier struct {
+	Notifier
+	formatter *Formatter
+}
+
+func (d dryRunNotifier) String() string {
+	return notifierName(d.Notifier)
+}
+
+func (d dryRunNotifier) unwrap() Notifier {
+	return d.Notifier
+}
+
+func (d dryRunNotifier) Send(_ context.Context, event Event) error {
+	title, body, err := d.formatter.format(event)
+	if err != nil {
+		return err
+	}
+	slog.Info("dry run, notification not sent", "notifier", d.String(), "title", title, "body", body)
+	return nil
+}
+
+// buildNotifiers creates the notifiers of configs, which must have been validated, the ones holding a connection
+// through pool. With dryRun every notifier only logs its messages.
+func buildNotifiers(configs []NotifierConfig, client *http.Client, formatter *Formatter, dryRun bool, pool *connectionPool) (MultiNotifier, error) {
+	var notifiers MultiNotifier
+	// add wraps n with the behaviors set by c
+	add := func(c NotifierConfig, n Notifier) {
+		if dryRun {
+			n = dryRunNotifier{Notifier: n, formatter: formatter}
+		}
+		if c.MinMagnitude > 0 {
+			n = minMagnitudeNotifier{Notifier: n, minMagnitude: c.MinMagnitude}
+		}
+		notifiers = append(notifiers, n)
+	}
+	for _, c := range configs {
+		var n Notifier
+		switch c.Type {
+		case "bark":
+			threshold := c.EmergencyMagnitude
+			if threshold == 0 {
+				threshold = defaultCriticalMagnitude
+			}
+			// a notifier per device, so that the retry queue only resends to the failed ones
+			for _, key := range c.barkKeys() {
+				add(c, &BarkNotifier{
+					Keys:              []string{key},
+					CriticalMagnitude: threshold,
+					Group:             orDefault(c.Group, defaultBarkGroup),
+					Icon:              c.Icon,
+					Client:            client,
+					Formatter:         formatter,
+				})
+			}
+			continue
+		case "telegram":
+			n = &TelegramNotifier{Token: c.Token, ChatID: c.Chat, Client: client, Formatter: formatter}
+		case "webhook":
+			header, err := parseHeaders(c.Headers)
+			if err != nil {
+				return nil, err
+			}
+			n = &WebhookNotifier{URL: c.URL, Header: header, Client: client}
+		case "pushover":
+			threshold := c.EmergencyMagnitude
+			if threshold == 0 {
+				threshold = defaultEmergencyMagnitude
+			}
+			n = &PushoverNotifier{Token: c.Token, User: c.User, EmergencyMagnitude: threshold, Client: client, Formatter: formatter}
+		case "ntfy":
+			n = &NtfyNotifier{URL: c.URL, Token: c.Token, Client: client, Formatter: formatter}
+		case "dingtalk":
+			n = &DingTalkNotifier{URL: c.URL, Secret: c.Secret, Client: client, Formatter: formatter}
+		case "wecom":
+			n = &WeComNotifier{URL: c.URL, Client: client, Formatter: formatter}
+		case "feishu":
+			n = &FeishuNotifier{URL: c.URL, Secret: c.Secret, Client: client, Formatter: formatter}
+		case "serverchan":
+			n = &ServerChanNotifier{SendKey: c.Key, Client: client, Formatter: formatter}
+		case "gotify":
+			n = &GotifyNotifier{URL: c.URL, Token: c.Token, Client: client, Formatter: formatter}
+		case "email":
+			port := c.Port
+			if port == 0 {
+				port = defaultSMTPPort
+			}
+			n = &EmailNotifier{
+				Host:      c.Host,
+				Port:      port,
+				Username:  c.Username,
+				Password:  c.Password,
+				From:      c.From,
+				To:        c.To,
+				Formatter: formatter,
+			}
+		case "discord":
+			n = &DiscordNotifier{URL: c.URL, Client: client, Formatter: formatter}
+		case "slack":
+			n = &SlackNotifier{URL: c.URL, Client: client, Formatter: formatter}
+		case "mqtt":
+			n = pool.get(c, func() Notifier {
+				return &MQTTNotifier{Broker: c.URL, Topic: c.Topic, Username: c.Username, Password: c.Password, ClientID: c.ClientID, QoS: byte(c.QoS), Timeout: client.Timeout}
+			})
+		case "nats":
+			n = pool.get(c, func() Notifier {
+				return &NATSNotifier{URL: c.URL, Subject: c.Topic, Token: c.Token}
+			})
+		case "kafka":
+			n = pool.get(c, func() Notifier {
+				return &KafkaNotifier{Brokers: c.Brokers, Topic: c.Topic}
+			})
+		case "redis":
+			n = pool.get(c, func() Notifier {
+				return &RedisNotifier{Addr: c.URL, Password: c.Password, Channel: c.Topic}
+			})
+		default:
+			return nil, fmt.Errorf("unknown notifier type %q", c.Type)
+		}
+		add(c, n)
+	}
+	return notifiers, nil
+}
+
+const (
+	colorRed    = 0xe74c3c
+	colorOrange = 0xe67e22
+	colorYellow = 0xf1c40f
+)
+
+// magnitudeColor is the RGB color of a message by magnitude.
+func magnitudeColor(magnitude float64) int {
+	switch {
+	case magnitude >= 6:
+		return colorRed
+	case magnitude >= 4.5:
+		return colorOrange
+	default:
+		return colorYellow
+	}
+}
+
+func notifierName(n Notifier) string {
+	if s, ok := n.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", n)
+}
+
+// notification sends the events of ch until ctx is done, then keeps draining the events still buffered in ch
+// until it is closed or drainTimeout elapses.
+func notification(ctx context.Context, ch <-chan notice, notifier Notifier, drainTimeout time.Duration) {
+	defer func() {
+		slog.Info("notification exiting...")
+	}()
+	send := func(ctx context.Context, n notice) {
+		event := n.event
+		ctx, span := tracer.Start(trace.ContextWithSpanContext(ctx, n.span), "notify",
+			trace.WithAttributes(attribute.Int("event.id", event.EventId), attribute.Float64("event.magnitude", event.Magnitude)))
+		defer span.End()
+		if err := notifier.Send(ctx, event); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			slog.Error("send notification failed", "err", err)
+		}
+	}
+	// an in-flight notification is completed even if shutdown is requested meanwhile
+	sendCtx := context.WithoutCancel(ctx)
+	for {
+		select {
+		case n, ok := <-ch:
+			if !ok {
+				return
+			}
+			send(sendCtx, n)
+		case <-ctx.Done():
+			drainCtx, cancel := context.WithTimeout(sendCtx, drainTimeout)
+			defer cancel()
+			for {
+				select {
+				case n, ok := <-ch:
+					if !ok {
+						return
+					}
+					send(drainCtx, n)
+				case <-drainCtx.Done():
+					slog.Warn("drain notifications timeout", "dropped", len(ch))
+					return
+				}
+			}
+		}
+	}
+}
+
+// postJSON sends payload as a JSON request body with the extra header and returns the response status code and body.
+// A 429 Too Many Requests fails with a rateLimitError.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, payload any) (int, []byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return 0, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err = rateLimited(response); err != nil {
+		return response.StatusCode, body, err
+	}
+	return response.StatusCode, body, nil
+}
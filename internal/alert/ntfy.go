@@ -0,0 +1,81 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+)
+
+// NtfyNotifier publishes events to a topic of an ntfy server, like https://ntfy.sh/<topic>.
+type NtfyNotifier struct {
+	URL string
+	// Token is the access token of a protected topic, empty for a public one.
+	Token     string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+func (n *NtfyNotifier) String() string {
+	return "ntfy"
+}
+
+// ntfyPriority maps the magnitude to an ntfy priority and tags, from 2 (low) to 5 (urgent).
+func ntfyPriority(magnitude float64) (int, string) {
+	switch {
+	case magnitude >= 6:
+		return 5, "rotating_light"
+	case magnitude >= 5:
+		return 4, "warning"
+	case magnitude >= 4:
+		return 3, "warning"
+	default:
+		return 2, "information_source"
+	}
+}
+
+func (n *NtfyNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := n.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	priority, tags := ntfyPriority(event.Magnitude)
+	if event.Tsunami {
+		priority, tags = 5, "rotating_light,ocean"
+	}
+	// ntfy decodes the RFC 2047 encoded words of non-ASCII headers
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", title))
+	req.Header.Set("Priority", strconv.Itoa(priority))
+	req.Header.Set("Tags", tags)
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	response, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if err = rateLimited(response); err != nil {
+		return err
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("ntfy: %w", newStatusError(response.StatusCode, data))
+	}
+	slog.Info("notification successfully", "notifier", "ntfy")
+	return nil
+}
@@ -0,0 +1,45 @@
+package alert
+
+import "time"
+
+const (
+	// idleBackoffPolls is the number of consecutive empty polls that doubles the interval.
+	idleBackoffPolls = 10
+	// activeWindow is how long polls stay at the minimum interval after an event is found.
+	activeWindow = 5 * time.Minute
+)
+
+// pacer adapts the polling interval: it backs off from interval toward max while the polls
+// are empty and polls at min for activeWindow after an event, min and max only apply when they
+// are below and above interval respectively.
+type pacer struct {
+	interval, min, max time.Duration
+
+	idle        int
+	activeUntil time.Time
+}
+
+func newPacer(cfg *Config) *pacer {
+	return &pacer{interval: cfg.Interval, min: cfg.MinInterval, max: cfg.MaxInterval}
+}
+
+// next records whether the last poll found an event and returns the delay before the next poll.
+func (p *pacer) next(found bool, now time.Time) time.Duration {
+	if found {
+		p.idle = 0
+		p.activeUntil = now.Add(activeWindow)
+	} else {
+		p.idle++
+	}
+	if p.min > 0 && p.min < p.interval && now.Before(p.activeUntil) {
+		return p.min
+	}
+	d := p.interval
+	for i := idleBackoffPolls; i <= p.idle && d < p.max; i += idleBackoffPolls {
+		d *= 2
+	}
+	if d > p.interval && d > p.max {
+		d = p.max
+	}
+	return d
+}
@@ -0,0 +1,85 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// defaultEmergencyMagnitude is the magnitude from which Pushover sends emergency notifications by default.
+const defaultEmergencyMagnitude = 7
+
+// PushoverNotifier sends events to a Pushover user, with a priority raised by the magnitude.
+type PushoverNotifier struct {
+	Token string
+	User  string
+	// EmergencyMagnitude is the magnitude from which the notification has the emergency priority,
+	// repeated until acknowledged by the user.
+	EmergencyMagnitude float64
+	Client             *http.Client
+	Formatter          *Formatter
+}
+
+type pushoverResponse struct {
+	Status int      `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+func (p *PushoverNotifier) String() string {
+	return "pushover"
+}
+
+// priority maps the magnitude to a Pushover priority, from -1 (quiet) to 2 (emergency).
+func (p *PushoverNotifier) priority(magnitude float64) int {
+	switch {
+	case magnitude >= p.EmergencyMagnitude:
+		return 2
+	case magnitude >= 5:
+		return 1
+	case magnitude >= 3:
+		return 0
+	default:
+		return -1
+	}
+}
+
+func (p *PushoverNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := p.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	priority := p.priority(event.Magnitude)
+	if event.Tsunami {
+		priority = 2
+	}
+	payload := map[string]any{
+		"token":    p.Token,
+		"user":     p.User,
+		"title":    title,
+		"message":  body,
+		"priority": priority,
+	}
+	if priority == 2 {
+		// emergency notifications are repeated every retry seconds until acknowledged or expired
+		payload["retry"] = 60
+		payload["expire"] = 3600
+	}
+	status, data, err := postJSON(ctx, p.Client, pushoverURL, nil, payload)
+	if err != nil {
+		return err
+	}
+	var resp pushoverResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("pushover: decode response with status %d: %w", status, err)
+	}
+	if resp.Status != 1 {
+		return rejected(status, fmt.Errorf("pushover: %s", strings.Join(resp.Errors, ", ")))
+	}
+	slog.Info("notification successfully", "notifier", "pushover")
+	return nil
+}
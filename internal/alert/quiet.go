@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// quietHours is a daily window of minutes since midnight in a timezone, wrapping across midnight when end is before start.
+type quietHours struct {
+	start, end int
+	tz         *time.Location
+}
+
+// parseQuietHours parses the "15:04" bounds of the window.
+func parseQuietHours(start, end string, tz *time.Location) (quietHours, error) {
+	s, err := time.Parse("15:04", start)
+	if err != nil {
+		return quietHours{}, fmt.Errorf("quiet-start: %w", err)
+	}
+	e, err := time.Parse("15:04", end)
+	if err != nil {
+		return quietHours{}, fmt.Errorf("quiet-end: %w", err)
+	}
+	return quietHours{start: s.Hour()*60 + s.Minute(), end: e.Hour()*60 + e.Minute(), tz: tz}, nil
+}
+
+func (q quietHours) contains(t time.Time) bool {
+	t = t.In(q.tz)
+	m := t.Hour()*60 + t.Minute()
+	if q.start <= q.end {
+		return q.start <= m && m < q.end
+	}
+	return m >= q.start || m < q.end
+}
+
+// quietNotifier holds back the events below overrideMagnitude during the quiet hours, except possible tsunamis.
+type quietNotifier struct {
+	Notifier
+	hours             quietHours
+	overrideMagnitude float64
+}
+
+func (q quietNotifier) Send(ctx context.Context, event Event) error {
+	if event.Magnitude < q.overrideMagnitude && !event.Tsunami && q.hours.contains(time.Now()) {
+		slog.Info("quiet hours, notification not sent", "event", event)
+		return nil
+	}
+	return q.Notifier.Send(ctx, event)
+}
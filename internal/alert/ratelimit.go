@@ -0,0 +1,50 @@
+package alert
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// tokenBucket allows bursts of up to capacity events, refilled at rate tokens per second.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	rate     float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int, now time.Time) *tokenBucket {
+	return &tokenBucket{capacity: float64(perMinute), rate: float64(perMinute) / 60, tokens: float64(perMinute), last: now}
+}
+
+// take consumes a token if one is available.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// limitedNotifier drops the notifications beyond the bucket, except the events of at least bypassMagnitude
+// and the possible tsunamis which are always sent without consuming a token.
+type limitedNotifier struct {
+	Notifier
+	bucket          *tokenBucket
+	bypassMagnitude float64
+}
+
+func (l limitedNotifier) Send(ctx context.Context, event Event) error {
+	if event.Magnitude < l.bypassMagnitude && !event.Tsunami && !l.bucket.take(time.Now()) {
+		slog.Warn("too many notifications, notification dropped", "event", event)
+		return nil
+	}
+	return l.Notifier.Send(ctx, event)
+}
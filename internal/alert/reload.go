@@ -0,0 +1,172 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// swapNotifier sends to the notifier swapped in by the last reload, see Run.
+type swapNotifier struct {
+	mu        sync.Mutex
+	notifier  Notifier
+	notifiers MultiNotifier
+	// inflight are the sends in progress with notifier.
+	inflight *sync.WaitGroup
+}
+
+func newSwapNotifier(n Notifier, notifiers MultiNotifier) *swapNotifier {
+	return &swapNotifier{notifier: n, notifiers: notifiers, inflight: new(sync.WaitGroup)}
+}
+
+func (s *swapNotifier) Send(ctx context.Context, event Event) error {
+	s.mu.Lock()
+	n, inflight := s.notifier, s.inflight
+	inflight.Add(1)
+	s.mu.Unlock()
+	defer inflight.Done()
+	return n.Send(ctx, event)
+}
+
+// String lists the names of the notifiers sent to.
+func (s *swapNotifier) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.notifiers))
+	for i, n := range s.notifiers {
+		names[i] = notifierName(n)
+	}
+	return strings.Join(names, ",")
+}
+
+// swap replaces n sending to notifiers, the in-flight notifications complete with the previous one and are waited
+// for by the returned group.
+func (s *swapNotifier) swap(n Notifier, notifiers MultiNotifier) *sync.WaitGroup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inflight := s.inflight
+	s.notifier, s.notifiers, s.inflight = n, notifiers, new(sync.WaitGroup)
+	return inflight
+}
+
+// connectionPool keeps the notifiers holding a connection by their config, so that a reload keeping the config of
+// one reuses it instead of connecting again, like an MQTT client its replacement with the same client id would
+// kick off the broker.
+type connectionPool struct {
+	// kept are the notifiers in use, added the ones of the notifiers being built, see commit.
+	kept, added map[string]Notifier
+}
+
+// get returns the notifier with the config c, created by create unless it is kept.
+func (p *connectionPool) get(c NotifierConfig, create func() Notifier) Notifier {
+	data, _ := json.Marshal(c)
+	key := string(data)
+	n, ok := p.added[key]
+	if !ok {
+		n, ok = p.kept[key]
+	}
+	if !ok {
+		n = create()
+	}
+	if p.added == nil {
+		p.added = make(map[string]Notifier)
+	}
+	p.added[key] = n
+	return n
+}
+
+// commit keeps the notifiers got since the last commit and returns the previous ones not got again, to be closed.
+func (p *connectionPool) commit() MultiNotifier {
+	var retired MultiNotifier
+	for key, n := range p.kept {
+		if _, ok := p.added[key]; !ok {
+			retired = append(retired, n)
+		}
+	}
+	p.kept, p.added = p.added, nil
+	return retired
+}
+
+// rollback forgets the notifiers got since the last commit, for a failed build.
+func (p *connectionPool) rollback() {
+	p.added = nil
+}
+
+// connections returns the notifiers in use.
+func (p *connectionPool) connections() MultiNotifier {
+	var notifiers MultiNotifier
+	for _, n := range p.kept {
+		notifiers = append(notifiers, n)
+	}
+	return notifiers
+}
+
+// newNotifier builds the notifiers of cfg and of its subscriptions, retried through queue when not nil, and the
+// notifier sending to them behind the quiet hours and the rate limit. The notifiers holding a connection are got
+// from pool, committed once built.
+func newNotifier(cfg *Config, client *http.Client, queue *retryQueue, pool *connectionPool) (MultiNotifier, Notifier, error) {
+	notifiers, notifier, err := buildNotifier(cfg, client, queue, pool)
+	if err != nil {
+		pool.rollback()
+	}
+	return notifiers, notifier, err
+}
+
+func buildNotifier(cfg *Config, client *http.Client, queue *retryQueue, pool *connectionPool) (MultiNotifier, Notifier, error) {
+	tz, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+	formatter, err := NewFormatter(cfg.MessageTemplate, cfg.Lang, cfg.MapURL, tz)
+	if err != nil {
+		return nil, nil, err
+	}
+	notifiers, err := buildNotifiers(cfg.notifierConfigs(), client, formatter, cfg.DryRun, pool)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(cfg.Subscriptions) > 0 {
+		// the events passing only the filter of a subscription reach the top-level notifiers too
+		for i, n := range notifiers {
+			notifiers[i] = subscribedNotifier{Notifier: n, filter: cfg.Filter, sWaveVelocity: cfg.SWaveVelocity, attenuation: cfg.Attenuation}
+		}
+	}
+	for _, s := range cfg.Subscriptions {
+		subscribed, err := buildNotifiers(s.Notifiers, client, formatter, cfg.DryRun, pool)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, n := range subscribed {
+			notifiers = append(notifiers, subscribedNotifier{
+				Notifier:      n,
+				filter:        s.filter(cfg.Filter),
+				sWaveVelocity: cfg.SWaveVelocity,
+				attenuation:   cfg.Attenuation,
+			})
+		}
+	}
+	if queue != nil {
+		for i, n := range notifiers {
+			notifiers[i] = retriedNotifier{Notifier: n, queue: queue}
+		}
+	}
+	var notifier Notifier = notifiers
+	if cfg.QuietStart != "" {
+		hours, err := parseQuietHours(cfg.QuietStart, cfg.QuietEnd, tz)
+		if err != nil {
+			return nil, nil, err
+		}
+		notifier = quietNotifier{Notifier: notifier, hours: hours, overrideMagnitude: cfg.QuietOverrideMagnitude}
+	}
+	if cfg.MaxNotificationsPerMinute > 0 {
+		notifier = limitedNotifier{
+			Notifier:        notifier,
+			bucket:          newTokenBucket(cfg.MaxNotificationsPerMinute, time.Now()),
+			bypassMagnitude: cfg.RateLimitBypassMagnitude,
+		}
+	}
+	return notifiers, notifier, nil
+}
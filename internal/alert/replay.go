@@ -0,0 +1,83 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// ReplaySource replays recorded events as if they were happening now, for testing the filters and notifiers.
+type ReplaySource struct {
+	// Events are sorted by the time they are replayed at.
+	Events []Event
+	// Instant replays every event on the first poll instead of respecting their relative timestamps.
+	Instant bool
+
+	next int
+}
+
+// LoadReplaySource reads path, a recorded Response of the China EEW feed or a JSON array of Event,
+// and shifts the timestamps of the events so that the first one starts at start.
+func LoadReplaySource(path string, instant bool, start time.Time) (*ReplaySource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &events)
+	} else {
+		var resp Response
+		err = json.Unmarshal(data, &resp)
+		events = resp.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse replay %s: %w", path, err)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("replay %s: no event", path)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return replayAt(events[i]) < replayAt(events[j])
+	})
+	first := events[0].StartAt
+	for _, e := range events {
+		first = min(first, e.StartAt)
+	}
+	offset := start.UnixMilli() - first
+	for i := range events {
+		events[i].StartAt += offset
+		if events[i].UpdateAt != 0 {
+			events[i].UpdateAt += offset
+		}
+	}
+	return &ReplaySource{Events: events, Instant: instant}, nil
+}
+
+// replayAt is the unix milli time an event was published, its last update if any.
+func replayAt(event Event) int64 {
+	return max(event.StartAt, event.UpdateAt)
+}
+
+// Poll returns the events due by now, and io.EOF once every event has been replayed.
+func (s *ReplaySource) Poll(_ context.Context, _ int64) ([]Event, error) {
+	if s.next == len(s.Events) {
+		return nil, io.EOF
+	}
+	end := len(s.Events)
+	if !s.Instant {
+		now := time.Now().UnixMilli()
+		end = s.next + sort.Search(len(s.Events)-s.next, func(i int) bool {
+			return replayAt(s.Events[s.next+i]) > now
+		})
+	}
+	events := s.Events[s.next:end]
+	s.next = end
+	return events, nil
+}
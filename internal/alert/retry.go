@@ -0,0 +1,159 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxRetryDelay = 30 * time.Second
+	// maxPollRetryAfter bounds the delay a Retry-After of the upstream puts off the next poll by, unless
+	// MaxInterval is longer.
+	maxPollRetryAfter = 2 * time.Minute
+	// maxErrorBody is the number of bytes of a response body kept in a statusError.
+	maxErrorBody = 256
+)
+
+// statusError is returned for an HTTP response whose status is not acceptable.
+type statusError struct {
+	Code int
+	Body string
+	// RetryAfter is the delay asked by the Retry-After header of the response, 0 if none.
+	RetryAfter time.Duration
+}
+
+func newStatusError(code int, body []byte) *statusError {
+	if len(body) > maxErrorBody {
+		body = append(body[:maxErrorBody:maxErrorBody], "..."...)
+	}
+	return &statusError{Code: code, Body: string(body)}
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Code, e.Body)
+}
+
+// temporary reports whether the request should be retried: server errors and rate limiting are
+// transient while other client errors are not.
+func (e *statusError) temporary() bool {
+	return e.Code >= http.StatusInternalServerError || e.Code == http.StatusTooManyRequests
+}
+
+// rateLimitError is returned when the server asks to wait RetryAfter before the next request.
+type rateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
+
+// permanentError is the rejection of a notification by an API that resending will not fix, like an invalid key or chat.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// rejected returns err, a rejection reported in the body of a response with the HTTP status, as a permanentError
+// unless the status is a server error.
+func rejected(status int, err error) error {
+	if status >= http.StatusInternalServerError {
+		return err
+	}
+	return &permanentError{err: err}
+}
+
+func temporary(err error) bool {
+	var (
+		pe *permanentError
+		se *statusError
+	)
+	if errors.As(err, &pe) {
+		return false
+	}
+	if errors.As(err, &se) {
+		return se.temporary()
+	}
+	return true
+}
+
+// retryAfter returns the delay asked by a rateLimitError or a statusError in err, 0 if none.
+func retryAfter(err error) time.Duration {
+	var (
+		re *rateLimitError
+		se *statusError
+	)
+	switch {
+	case errors.As(err, &re):
+		return re.RetryAfter
+	case errors.As(err, &se):
+		return se.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter returns the delay asked by the Retry-After header of response, in seconds or an HTTP-date,
+// 0 if none or invalid.
+func parseRetryAfter(response *http.Response) time.Duration {
+	value := strings.TrimSpace(response.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// rateLimited returns a rateLimitError when response is a 429 Too Many Requests, nil otherwise.
+func rateLimited(response *http.Response) error {
+	if response.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	return &rateLimitError{RetryAfter: parseRetryAfter(response)}
+}
+
+// backoff returns the delay before the given retry attempt, doubling from base with jitter.
+func backoff(base time.Duration, attempt int) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retry calls fn until it succeeds, fails permanently or maxRetries is exhausted. It gives up when the server
+// asks to wait longer than maxRetryDelay, leaving the wait to the caller.
+func retry(ctx context.Context, maxRetries int, base time.Duration, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= maxRetries || !temporary(err) || ctx.Err() != nil || retryAfter(err) > maxRetryDelay {
+			return err
+		}
+		delay := max(backoff(base, attempt), retryAfter(err))
+		slog.Info("request failed, retrying", "attempt", attempt+1, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
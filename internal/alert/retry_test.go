@@ -0,0 +1,39 @@
+package alert
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		value string
+		// want is the expected delay, within a second for the dates rounded to the second
+		want time.Duration
+	}{
+		{"none", "", 0},
+		{"delta-seconds", "120", 2 * time.Minute},
+		{"delta-seconds with spaces", " 5 ", 5 * time.Second},
+		{"zero seconds", "0", 0},
+		{"negative seconds", "-5", 0},
+		{"HTTP-date", now.Add(time.Minute).UTC().Format(http.TimeFormat), time.Minute},
+		{"past HTTP-date", now.Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+		{"garbage", "soon", 0},
+		{"fractional seconds", "1.5", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			if tt.value != "" {
+				response.Header.Set("Retry-After", tt.value)
+			}
+			got := parseRetryAfter(response)
+			if got < tt.want-time.Second || got > tt.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
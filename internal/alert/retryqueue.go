@@ -0,0 +1,176 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	// retryQueueBaseDelay is the delay before the first resend of a failed notification, doubled on each following one.
+	retryQueueBaseDelay = 5 * time.Second
+	// retryQueueIdle is how long the retry queue sleeps while nothing is pending.
+	retryQueueIdle = time.Hour
+)
+
+// pendingNotification is a failed notification waiting in a retryQueue.
+type pendingNotification struct {
+	notifier Notifier
+	event    Event
+	// failedAt is the time of the first failure, attempt the number of resends so far.
+	failedAt time.Time
+	attempt  int
+	due      time.Time
+}
+
+// retryQueue resends in the background the notifications that failed temporarily, with backoff, until they
+// succeed, window has elapsed since their first failure or their event is older than maxAge. The oldest
+// pending notification is dropped when size of them are already pending.
+type retryQueue struct {
+	size           int
+	window, maxAge time.Duration
+
+	mu      sync.Mutex
+	pending []*pendingNotification
+	wake    chan struct{}
+}
+
+func newRetryQueue(size int, window, maxAge time.Duration) *retryQueue {
+	return &retryQueue{size: size, window: window, maxAge: maxAge, wake: make(chan struct{}, 1)}
+}
+
+// add queues the notification of event by n failed with err, due after the delay asked by the server if longer
+// than the backoff.
+func (q *retryQueue) add(n Notifier, event Event, err error, now time.Time) {
+	due := now.Add(max(backoff(retryQueueBaseDelay, 0), retryAfter(err)))
+	q.push(&pendingNotification{notifier: n, event: event, failedAt: now, due: due})
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *retryQueue) push(p *pendingNotification) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) >= q.size {
+		dropped := q.pending[0]
+		q.pending = q.pending[1:]
+		slog.Warn("retry queue is full, notification dropped", "notifier", notifierName(dropped.notifier), "event", dropped.event)
+	}
+	q.pending = append(q.pending, p)
+}
+
+// holds reports whether a pending notification is to be resent with one of notifiers, at the bottom of its wrappers.
+func (q *retryQueue) holds(notifiers MultiNotifier) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, p := range q.pending {
+		n := unwrapAll(p.notifier)
+		for _, other := range notifiers {
+			if n == other {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// run resends the due notifications until ctx is done, then drops the pending ones.
+func (q *retryQueue) run(ctx context.Context) {
+	for {
+		delay := q.resend(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if len(q.pending) > 0 {
+				slog.Warn("retry queue exiting, notifications dropped", "dropped", len(q.pending))
+			}
+			return
+		case <-q.wake:
+		case <-time.After(delay):
+		}
+	}
+}
+
+// resend sends the notifications due by now and returns the delay until the next one is due.
+func (q *retryQueue) resend(ctx context.Context, now time.Time) time.Duration {
+	q.mu.Lock()
+	var due []*pendingNotification
+	kept := q.pending[:0]
+	for _, p := range q.pending {
+		if p.due.After(now) {
+			kept = append(kept, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	q.pending = kept
+	q.mu.Unlock()
+
+	for _, p := range due {
+		name := notifierName(p.notifier)
+		switch {
+		case serverNow().Sub(time.UnixMilli(p.event.StartAt)) > q.maxAge:
+			slog.Warn("notification dropped from the retry queue", "reason", "out of date", "notifier", name, "event", p.event)
+			continue
+		case now.Sub(p.failedAt) > q.window:
+			slog.Warn("notification dropped from the retry queue", "reason", "retry window elapsed", "notifier", name, "event", p.event)
+			continue
+		}
+		err := p.notifier.Send(ctx, p.event)
+		if err == nil {
+			notificationsSentTotal.WithLabelValues(name).Inc()
+			slog.Info("notification sent after retrying", "notifier", name, "attempt", p.attempt+1)
+			continue
+		}
+		notificationErrorsTotal.WithLabelValues(name).Inc()
+		if !temporary(err) {
+			slog.Warn("notification dropped from the retry queue", "reason", "permanent failure", "notifier", name, "event", p.event, "err", err)
+			continue
+		}
+		p.attempt++
+		p.due = time.Now().Add(max(backoff(retryQueueBaseDelay, p.attempt), retryAfter(err)))
+		slog.Info("notification failed again, retrying", "notifier", name, "attempt", p.attempt, "due", p.due, "err", err)
+		q.push(p)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delay := retryQueueIdle
+	for _, p := range q.pending {
+		delay = min(delay, time.Until(p.due))
+	}
+	return delay
+}
+
+// retriedNotifier queues the temporary failures of the wrapped notifier to be resent by a retryQueue.
+type retriedNotifier struct {
+	Notifier
+	queue *retryQueue
+}
+
+func (r retriedNotifier) String() string {
+	return notifierName(r.Notifier)
+}
+
+func (r retriedNotifier) unwrap() Notifier {
+	return r.Notifier
+}
+
+func (r retriedNotifier) accept(event Event) bool {
+	g, ok := r.Notifier.(gate)
+	return !ok || g.accept(event)
+}
+
+func (r retriedNotifier) Send(ctx context.Context, event Event) error {
+	err := r.Notifier.Send(ctx, event)
+	if err != nil && temporary(err) && ctx.Err() == nil {
+		r.queue.add(r.Notifier, event, err, time.Now())
+		return fmt.Errorf("%w, queued for retry", err)
+	}
+	return err
+}
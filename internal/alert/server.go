@@ -0,0 +1,53 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// servers groups the HTTP handlers by listen address so endpoints configured on the same address share one server.
+type servers map[string]*http.ServeMux
+
+// handle registers handler for pattern on addr, an empty addr disables the endpoint.
+func (s servers) handle(addr, pattern string, handler http.Handler) {
+	if addr == "" {
+		return
+	}
+	mux, ok := s[addr]
+	if !ok {
+		mux = http.NewServeMux()
+		s[addr] = mux
+	}
+	mux.Handle(pattern, handler)
+}
+
+func (s servers) run(ctx context.Context) {
+	for addr, mux := range s {
+		go serve(ctx, addr, mux)
+	}
+}
+
+// serve runs an HTTP server on addr until ctx is done.
+func serve(ctx context.Context, addr string, handler http.Handler) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		// the requests are canceled on shutdown so that the event streams end
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	slog.Info("http server listening", "addr", addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("http server", "addr", addr, "err", err)
+	}
+}
@@ -0,0 +1,76 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// ServerChanNotifier pushes events to WeChat through Server酱 (ServerChan).
+type ServerChanNotifier struct {
+	SendKey   string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+type serverChanResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// serverChan3Key matches the SendKey of Server酱³, sent to the host of its uid instead of sctapi.
+var serverChan3Key = regexp.MustCompile(`^sctp(\d+)t`)
+
+func (s *ServerChanNotifier) String() string {
+	return "serverchan"
+}
+
+func (s *ServerChanNotifier) url() string {
+	if m := serverChan3Key.FindStringSubmatch(s.SendKey); m != nil {
+		return fmt.Sprintf("https://%s.push.ft07.com/send/%s.send", m[1], s.SendKey)
+	}
+	return fmt.Sprintf("https://sctapi.ftqq.com/%s.send", s.SendKey)
+}
+
+func (s *ServerChanNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := s.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	form := url.Values{"title": {title}, "desp": {body}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if err = rateLimited(response); err != nil {
+		return err
+	}
+	var resp serverChanResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("serverchan: decode response with status %d: %w", response.StatusCode, err)
+	}
+	if resp.Code != 0 {
+		return rejected(response.StatusCode, fmt.Errorf("serverchan: code %d: %s", resp.Code, resp.Message))
+	}
+	slog.Info("notification successfully", "notifier", "serverchan")
+	return nil
+}
@@ -0,0 +1,52 @@
+package alert
+
+// Severity is a quick read of how serious an event is, from its magnitude and depth.
+type Severity string
+
+const (
+	SeverityMinor    Severity = "minor"
+	SeverityModerate Severity = "moderate"
+	SeverityStrong   Severity = "strong"
+	SeverityMajor    Severity = "major"
+)
+
+// severityNames are the names of the severities in each language of messageTemplates but en, which uses the values.
+var severityNames = map[string]map[Severity]string{
+	"zh": {
+		SeverityMinor:    "轻微",
+		SeverityModerate: "中等",
+		SeverityStrong:   "强烈",
+		SeverityMajor:    "重大",
+	},
+}
+
+// In returns the name of the severity in lang, the value itself for an unknown lang.
+func (s Severity) In(lang string) string {
+	if name, ok := severityNames[lang][s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// classifySeverity is major from magnitude 7 and for the possible tsunamis, strong from 6, moderate from 4
+// and minor below. The shaking of intermediate events, at least 70 km deep, and of deep events, at least 300 km,
+// is weaker at the surface so their magnitude counts 0.5 and 1 less.
+func classifySeverity(event Event) Severity {
+	magnitude := event.Magnitude
+	switch {
+	case event.Depth >= 300:
+		magnitude--
+	case event.Depth >= 70:
+		magnitude -= 0.5
+	}
+	switch {
+	case event.Tsunami || magnitude >= 7:
+		return SeverityMajor
+	case magnitude >= 6:
+		return SeverityStrong
+	case magnitude >= 4:
+		return SeverityModerate
+	default:
+		return SeverityMinor
+	}
+}
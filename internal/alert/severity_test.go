@@ -0,0 +1,34 @@
+package alert
+
+import "testing"
+
+func TestClassifySeverity(t *testing.T) {
+	tests := []struct {
+		name  string
+		event Event
+		want  Severity
+	}{
+		{"below moderate", Event{Magnitude: 3.9, Depth: 10}, SeverityMinor},
+		{"moderate from 4", Event{Magnitude: 4, Depth: 10}, SeverityModerate},
+		{"below strong", Event{Magnitude: 5.9, Depth: 10}, SeverityModerate},
+		{"strong from 6", Event{Magnitude: 6, Depth: 10}, SeverityStrong},
+		{"below major", Event{Magnitude: 6.9, Depth: 10}, SeverityStrong},
+		{"major from 7", Event{Magnitude: 7, Depth: 10}, SeverityMajor},
+		{"shallow above 70 km", Event{Magnitude: 6, Depth: 69.9}, SeverityStrong},
+		{"intermediate from 70 km counts 0.5 less", Event{Magnitude: 6, Depth: 70}, SeverityModerate},
+		{"intermediate reaching strong", Event{Magnitude: 6.5, Depth: 70}, SeverityStrong},
+		{"intermediate above 300 km", Event{Magnitude: 7.4, Depth: 299.9}, SeverityStrong},
+		{"deep from 300 km counts 1 less", Event{Magnitude: 7.9, Depth: 300}, SeverityStrong},
+		{"deep reaching major", Event{Magnitude: 8, Depth: 300}, SeverityMajor},
+		{"deep below moderate", Event{Magnitude: 4.9, Depth: 400}, SeverityMinor},
+		{"tsunami is major whatever the magnitude", Event{Magnitude: 5, Depth: 10, Tsunami: true}, SeverityMajor},
+		{"tsunami is major whatever the depth", Event{Magnitude: 5, Depth: 400, Tsunami: true}, SeverityMajor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifySeverity(tt.event); got != tt.want {
+				t.Errorf("classifySeverity(M%.1f at %.1f km) = %s, want %s", tt.event.Magnitude, tt.event.Depth, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package alert
+
+import "time"
+
+// simulatedEpicenters is the default epicenter of the simulated event in each language of messageTemplates.
+var simulatedEpicenters = map[string]string{
+	"zh": "模拟地震,请忽略",
+	"en": "Simulated earthquake, please ignore",
+}
+
+// simulatedEvent is the event sent by -simulate, at the configured location or Beijing when none is configured.
+func simulatedEvent(cfg *Config, now time.Time) Event {
+	event := Event{
+		Updates:   1,
+		Latitude:  39.9,
+		Longitude: 116.4,
+		Depth:     10,
+		Epicenter: cfg.SimulateEpicenter,
+		StartAt:   now.UnixMilli(),
+		UpdateAt:  now.UnixMilli(),
+		Magnitude: cfg.SimulateMagnitude,
+		InsideNet: 1,
+	}
+	if event.Epicenter == "" {
+		event.Epicenter = simulatedEpicenters[cfg.Lang]
+	}
+	if cfg.Filter.hasLocation() {
+		event.Latitude, event.Longitude = cfg.Filter.location()
+		event.Local = locate(event, event.Latitude, event.Longitude, cfg.SWaveVelocity, cfg.Attenuation)
+	}
+	event.Tsunami = tsunamiRisk(event, cfg.TsunamiMagnitude, cfg.OffshoreKeywords)
+	return event
+}
@@ -0,0 +1,45 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// SlackNotifier posts events to a Slack incoming webhook, as an attachment colored by magnitude.
+type SlackNotifier struct {
+	URL       string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+func (s *SlackNotifier) String() string {
+	return "slack"
+}
+
+func (s *SlackNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := s.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	status, data, err := postJSON(ctx, s.Client, s.URL, nil, map[string]any{
+		// text is the fallback of the notifications
+		"text": title,
+		"attachments": []any{map[string]any{
+			"color": fmt.Sprintf("#%06x", magnitudeColor(event.Magnitude)),
+			"blocks": []any{
+				map[string]any{"type": "header", "text": map[string]string{"type": "plain_text", "text": title}},
+				map[string]any{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": body}},
+			},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("slack: %w", newStatusError(status, data))
+	}
+	slog.Info("notification successfully", "notifier", "slack")
+	return nil
+}
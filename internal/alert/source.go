@@ -0,0 +1,199 @@
+package alert
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Source fetches events from an upstream feed, normalized into Event.
+type Source interface {
+	// Poll returns the events that started at or after since, a unix milli time,
+	// or io.EOF when the source will never return an event again.
+	Poll(ctx context.Context, since int64) ([]Event, error)
+}
+
+// lateSource is implemented by the sources publishing events late and out of origin-time order, like USGS. Their
+// polls return the events older than since too, and the loop leaves them to its seen cache rather than dropping
+// the events older than the newest one seen.
+type lateSource interface {
+	late() bool
+}
+
+// publishesLate reports whether the last poll of s was answered by a late source.
+func publishesLate(s Source) bool {
+	l, ok := s.(lateSource)
+	return ok && l.late()
+}
+
+// sources creates the Source of each name accepted by -source, a new feed only needs an entry here.
+var sources = map[string]func(cfg *Config, client *http.Client) Source{
+	"chinaeew": func(cfg *Config, client *http.Client) Source {
+		return &ChinaEEWSource{
+			URL:            orDefault(cfg.SourceURL, chinaEEWURL),
+			Client:         client,
+			MaxRetries:     cfg.MaxRetries,
+			RetryBaseDelay: cfg.RetryBaseDelay,
+			Updates:        cfg.Updates,
+		}
+	},
+	"usgs": func(cfg *Config, client *http.Client) Source {
+		return &USGSSource{
+			URL:            orDefault(cfg.SourceURL, usgsURL),
+			Client:         client,
+			MaxRetries:     cfg.MaxRetries,
+			RetryBaseDelay: cfg.RetryBaseDelay,
+		}
+	},
+}
+
+func newSource(cfg *Config, client *http.Client) (Source, error) {
+	if cfg.Replay != "" {
+		replay, err := LoadReplaySource(cfg.Replay, cfg.ReplayInstant, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return replay, nil
+	}
+	failover := &FailoverSource{
+		FailureThreshold: cfg.FailoverThreshold,
+		ProbeInterval:    cfg.FailbackInterval,
+	}
+	for _, s := range cfg.sourceSpecs() {
+		create, ok := sources[s.name]
+		if !ok {
+			return nil, fmt.Errorf("unknown source %q", s.name)
+		}
+		c := *cfg
+		c.SourceURL = s.url
+		failover.Sources = append(failover.Sources, create(&c, client))
+		failover.Names = append(failover.Names, s.name)
+	}
+	var source Source = failover
+	if len(failover.Sources) == 1 {
+		source = failover.Sources[0]
+	}
+	if cfg.BreakerThreshold > 0 {
+		source = &BreakerSource{Source: source, FailureThreshold: cfg.BreakerThreshold, Cooldown: cfg.BreakerCooldown}
+	}
+	return source, nil
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// validators are the ETag and Last-Modified of the last response of url, sent back so that the upstream
+// only returns a changed response.
+type validators struct {
+	url, etag, lastModified string
+}
+
+// query gets url with client and decodes the JSON body into T, retrying transient failures. The request is
+// conditional on v, when not nil, and a zero T is returned when the upstream answers it is not modified.
+func query[T any](ctx context.Context, client *http.Client, url string, v *validators, maxRetries int, retryBaseDelay time.Duration) (*T, error) {
+	var data []byte
+	err := retry(ctx, maxRetries, retryBaseDelay, func() error {
+		var err error
+		data, err = fetch(ctx, client, url, v)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var resp T
+	if data == nil {
+		return &resp, nil
+	}
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// fetch gets the body of url, or nil when the request conditional on v is answered not modified.
+func fetch(ctx context.Context, client *http.Client, url string, v *validators) (data []byte, err error) {
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		upstreamRequestsTotal.WithLabelValues(result).Inc()
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if v != nil && v.url == url {
+		if v.etag != "" {
+			req.Header.Set("If-None-Match", v.etag)
+		}
+		if v.lastModified != "" {
+			req.Header.Set("If-Modified-Since", v.lastModified)
+		}
+	}
+	start := time.Now()
+	response, err := client.Do(req)
+	upstreamRequestDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	recordServerDate(response.Header, time.Now())
+
+	body, err := decodedBody(response)
+	if err != nil {
+		return nil, err
+	}
+	data, err = io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotModified && v != nil && v.url == url {
+		return nil, nil
+	}
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		err := newStatusError(response.StatusCode, data)
+		err.RetryAfter = parseRetryAfter(response)
+		return nil, err
+	}
+	if v != nil {
+		// a server without validators gets unconditional requests
+		*v = validators{url: url, etag: response.Header.Get("ETag"), lastModified: response.Header.Get("Last-Modified")}
+	}
+	return data, nil
+}
+
+// decodedBody returns the body of response decompressed by its Content-Encoding, gzip or deflate, for the
+// compressed bodies the transport does not decompress transparently, like the ones sent unrequested.
+func decodedBody(response *http.Response) (io.Reader, error) {
+	var (
+		body io.Reader
+		err  error
+	)
+	switch strings.ToLower(response.Header.Get("Content-Encoding")) {
+	case "gzip", "x-gzip":
+		body, err = gzip.NewReader(response.Body)
+	case "deflate":
+		body, err = zlib.NewReader(response.Body)
+	default:
+		return response.Body, nil
+	}
+	if errors.Is(err, io.EOF) {
+		// an empty body, like the one of a not modified response
+		return strings.NewReader(""), nil
+	}
+	return body, err
+}
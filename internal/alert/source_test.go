@@ -0,0 +1,167 @@
+package alert
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuerySuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("start_at"); got != "42" {
+			t.Errorf("start_at = %q, want 42", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"data":[{"eventId":7,"updates":2,"epicenter":"四川","magnitude":5.1}]}`))
+	}))
+	defer srv.Close()
+
+	resp, err := query[Response](context.Background(), srv.Client(), srv.URL+"?start_at=42", nil, 0, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 {
+		t.Fatalf("got %d events, want 1", len(resp.Data))
+	}
+	if e := resp.Data[0]; e.EventId != 7 || e.Updates != 2 || e.Epicenter != "四川" || e.Magnitude != 5.1 {
+		t.Errorf("got %+v", e)
+	}
+}
+
+func TestQueryMalformedJSON(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"code":0,"data":[`))
+	}))
+	defer srv.Close()
+
+	_, err := query[Response](context.Background(), srv.Client(), srv.URL, nil, 3, time.Millisecond)
+	if err == nil {
+		t.Fatal("got no error for a malformed body")
+	}
+	// a complete response is not retried, whatever its body
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+}
+
+func TestQueryRetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":[{"eventId":1}]}`))
+	}))
+	defer srv.Close()
+
+	resp, err := query[Response](context.Background(), srv.Client(), srv.URL, nil, 3, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || requests.Load() != 3 {
+		t.Errorf("got %d events after %d requests, want 1 after 3", len(resp.Data), requests.Load())
+	}
+}
+
+func TestQueryStatusError(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     int
+		requests int32
+	}{
+		{"server error exhausts the retries", http.StatusBadGateway, 3},
+		{"client error is not retried", http.StatusNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				http.Error(w, "failed", tt.code)
+			}))
+			defer srv.Close()
+
+			_, err := query[Response](context.Background(), srv.Client(), srv.URL, nil, 2, time.Millisecond)
+			var se *statusError
+			if !errors.As(err, &se) {
+				t.Fatalf("got %v, want a statusError", err)
+			}
+			if se.Code != tt.code || se.Body != "failed\n" {
+				t.Errorf("got status %d with body %q", se.Code, se.Body)
+			}
+			if n := requests.Load(); n != tt.requests {
+				t.Errorf("got %d requests, want %d", n, tt.requests)
+			}
+		})
+	}
+}
+
+func TestQueryContextCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := query[Response](ctx, srv.Client(), srv.URL, nil, 3, time.Second)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("returned after %s, want as soon as canceled", elapsed)
+	}
+}
+
+func TestQueryCompressedBody(t *testing.T) {
+	const body = `{"code":0,"data":[{"eventId":7,"epicenter":"四川"},{"eventId":8,"epicenter":"云南"}]}`
+	compress := func(encoding string) []byte {
+		var buf bytes.Buffer
+		var w io.WriteCloser
+		switch encoding {
+		case "gzip", "x-gzip":
+			w = gzip.NewWriter(&buf)
+		case "deflate":
+			w = zlib.NewWriter(&buf)
+		default:
+			return []byte(body)
+		}
+		_, _ = w.Write([]byte(body))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+	for _, encoding := range []string{"gzip", "x-gzip", "GZIP", "deflate", ""} {
+		t.Run("encoding "+encoding, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if encoding != "" {
+					w.Header().Set("Content-Encoding", encoding)
+				}
+				_, _ = w.Write(compress(strings.ToLower(encoding)))
+			}))
+			defer srv.Close()
+			// the body is compressed unrequested, so the transport leaves it to decodedBody
+			client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+			resp, err := query[Response](context.Background(), client, srv.URL, nil, 0, time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Data) != 2 || resp.Data[0].EventId != 7 || resp.Data[1].Epicenter != "云南" {
+				t.Errorf("got %+v", resp.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// state is the polling progress persisted across restarts.
+type state struct {
+	LastTs      int64 `json:"lastTs"`
+	LastEventID int   `json:"lastEventId"`
+	Updates     int   `json:"updates"`
+}
+
+// loadState reads the state file, a missing file yields the zero state.
+func loadState(path string) (state, error) {
+	var st state
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	err = json.Unmarshal(data, &st)
+	return st, err
+}
+
+// saveState writes the state to a temporary file and renames it over path so a crash never leaves a partial file.
+func saveState(path string, st state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,220 @@
+package alert
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store keeps the history of detected events.
+type Store interface {
+	// Save inserts the event, or replaces the stored one with the same EventId when it has more updates.
+	Save(event Event) error
+	// Recent returns the latest n events by StartAt, newest first.
+	Recent(n int) ([]Event, error)
+	// Each calls fn with every stored event by StartAt, oldest first, until fn fails with the error returned.
+	Each(fn func(Event) error) error
+}
+
+// migrations upgrade the schema of SQLiteStore, the index of the last applied one plus 1 is kept in user_version.
+// Only append to it, never change an applied migration.
+var migrations = []string{
+	`CREATE TABLE events (
+		event_id   INTEGER PRIMARY KEY,
+		updates    INTEGER NOT NULL,
+		latitude   REAL    NOT NULL,
+		longitude  REAL    NOT NULL,
+		depth      REAL    NOT NULL,
+		epicenter  TEXT    NOT NULL,
+		start_at   INTEGER NOT NULL,
+		update_at  INTEGER NOT NULL,
+		magnitude  REAL    NOT NULL,
+		inside_net INTEGER NOT NULL,
+		sations    INTEGER NOT NULL
+	)`,
+	`CREATE INDEX events_start_at ON events (start_at)`,
+}
+
+// SQLiteStore is a Store in a SQLite database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens or creates the database at path and migrates its schema to the latest.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes the writes instead of failing them with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if err = migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate %s: %w", path, err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	for ; version < len(migrations); version++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec(migrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("version %d: %w", version+1, err)
+		}
+		if _, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) Save(event Event) error {
+	_, err := s.db.Exec(`INSERT INTO events
+		(event_id, updates, latitude, longitude, depth, epicenter, start_at, update_at, magnitude, inside_net, sations)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (event_id) DO UPDATE SET
+			updates = excluded.updates, latitude = excluded.latitude, longitude = excluded.longitude,
+			depth = excluded.depth, epicenter = excluded.epicenter, start_at = excluded.start_at,
+			update_at = excluded.update_at, magnitude = excluded.magnitude,
+			inside_net = excluded.inside_net, sations = excluded.sations
+		WHERE excluded.updates > events.updates`,
+		event.EventId, event.Updates, event.Latitude, event.Longitude, event.Depth, event.Epicenter,
+		event.StartAt, event.UpdateAt, event.Magnitude, event.InsideNet, event.Sations)
+	return err
+}
+
+func (s *SQLiteStore) Recent(n int) ([]Event, error) {
+	return s.query(`SELECT
+		event_id, updates, latitude, longitude, depth, epicenter, start_at, update_at, magnitude, inside_net, sations
+		FROM events ORDER BY start_at DESC LIMIT ?`, n)
+}
+
+// eachPageSize is the number of rows Each reads at a time.
+const eachPageSize = 500
+
+// Each reads the rows by pages without loading them all, calling fn once a page is read so that a slow fn,
+// like the write to a client, does not hold the only connection and block Save.
+func (s *SQLiteStore) Each(fn func(Event) error) error {
+	startAt, eventID := int64(math.MinInt64), 0
+	for {
+		events, err := s.after(startAt, eventID, eachPageSize)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if err = fn(e); err != nil {
+				return err
+			}
+		}
+		if len(events) < eachPageSize {
+			return nil
+		}
+		last := events[len(events)-1]
+		startAt, eventID = last.StartAt, last.EventId
+	}
+}
+
+// after returns the first n events ordered by start_at and event_id after the given ones.
+func (s *SQLiteStore) after(startAt int64, eventID, n int) ([]Event, error) {
+	return s.query(`SELECT
+		event_id, updates, latitude, longitude, depth, epicenter, start_at, update_at, magnitude, inside_net, sations
+		FROM events WHERE (start_at, event_id) > (?, ?) ORDER BY start_at, event_id LIMIT ?`, startAt, eventID, n)
+}
+
+// query reads all the events selected by the query, closing its rows before returning.
+func (s *SQLiteStore) query(query string, args ...any) ([]Event, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// scanEvent reads the event of the current row, selected with the columns in the order of the table.
+func scanEvent(rows *sql.Rows) (Event, error) {
+	var e Event
+	err := rows.Scan(&e.EventId, &e.Updates, &e.Latitude, &e.Longitude, &e.Depth, &e.Epicenter,
+		&e.StartAt, &e.UpdateAt, &e.Magnitude, &e.InsideNet, &e.Sations)
+	return e, err
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// memoryStoreSize is the number of events kept by memoryStore.
+const memoryStoreSize = 1000
+
+// memoryStore is the Store used without a database, keeping the latest events until exit.
+type memoryStore struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *memoryStore) Save(event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.events, func(e Event) bool { return e.EventId == event.EventId })
+	switch {
+	case i < 0:
+		s.events = append(s.events, event)
+	case event.Updates > s.events[i].Updates:
+		s.events[i] = event
+	default:
+		return nil
+	}
+	sort.SliceStable(s.events, func(i, j int) bool {
+		return s.events[i].StartAt > s.events[j].StartAt
+	})
+	if len(s.events) > memoryStoreSize {
+		s.events = s.events[:memoryStoreSize]
+	}
+	return nil
+}
+
+func (s *memoryStore) Recent(n int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n = min(n, len(s.events))
+	return append([]Event(nil), s.events[:n]...), nil
+}
+
+func (s *memoryStore) Each(fn func(Event) error) error {
+	s.mu.Lock()
+	events := slices.Clone(s.events)
+	s.mu.Unlock()
+	for i := len(events) - 1; i >= 0; i-- {
+		if err := fn(events[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
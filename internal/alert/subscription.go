@@ -0,0 +1,96 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// SubscriptionConfig notifies its notifiers of the events passing its filter, to alert several people by
+// different rules from one instance.
+type SubscriptionConfig struct {
+	Name string `yaml:"name"`
+	// Filter is the filter of the subscription, its max_event_age defaults to the one of the top-level filter.
+	Filter    FilterConfig     `yaml:"filter"`
+	Notifiers []NotifierConfig `yaml:"notifiers"`
+}
+
+// filter returns the filter of the subscription with the defaults of top.
+func (s SubscriptionConfig) filter(top FilterConfig) FilterConfig {
+	f := s.Filter
+	if f.MaxEventAge == 0 {
+		f.MaxEventAge = top.MaxEventAge
+	}
+	return f
+}
+
+func (s SubscriptionConfig) validate(top FilterConfig) error {
+	if err := s.filter(top).validate(); err != nil {
+		return err
+	}
+	if len(s.Notifiers) == 0 {
+		return errors.New("notifiers should have a value")
+	}
+	for _, n := range s.Notifiers {
+		if err := n.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejectReason reports why the event is notified to nobody at now, the reason of the top-level filter,
+// or "" when it passes the top-level filter or the one of a subscription.
+func (c *Config) rejectReason(event Event, now time.Time) string {
+	reason := c.Filter.rejectReason(event, now)
+	if reason == "" {
+		return ""
+	}
+	for _, s := range c.Subscriptions {
+		f := s.filter(c.Filter)
+		if f.rejectReason(relocate(event, f, c.SWaveVelocity, c.Attenuation), now) == "" {
+			return ""
+		}
+	}
+	return reason
+}
+
+// relocate estimates the event at the location of f, none when f has no location.
+func relocate(event Event, f FilterConfig, sWaveVelocity float64, attenuation Attenuation) Event {
+	event.Local = nil
+	if f.hasLocation() {
+		lat, lon := f.location()
+		event.Local = locate(event, lat, lon, sWaveVelocity, attenuation)
+	}
+	return event
+}
+
+// subscribedNotifier only sends the events passing filter to the wrapped notifier, estimated at the location
+// of filter.
+type subscribedNotifier struct {
+	Notifier
+	filter        FilterConfig
+	sWaveVelocity float64
+	attenuation   Attenuation
+}
+
+func (s subscribedNotifier) String() string {
+	return notifierName(s.Notifier)
+}
+
+func (s subscribedNotifier) unwrap() Notifier {
+	return s.Notifier
+}
+
+func (s subscribedNotifier) accept(event Event) bool {
+	event = relocate(event, s.filter, s.sWaveVelocity, s.attenuation)
+	if s.filter.rejectReason(event, serverNow()) != "" {
+		return false
+	}
+	g, ok := s.Notifier.(gate)
+	return !ok || g.accept(event)
+}
+
+func (s subscribedNotifier) Send(ctx context.Context, event Event) error {
+	return s.Notifier.Send(ctx, relocate(event, s.filter, s.sWaveVelocity, s.attenuation))
+}
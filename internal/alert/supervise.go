@@ -0,0 +1,47 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+)
+
+// restartDelay is the pause before a panicked goroutine is restarted.
+const restartDelay = 5 * time.Second
+
+// supervise runs fn until it returns normally or ctx is done, restarting it after restartDelay when it panics.
+func supervise(ctx context.Context, name string, fn func()) {
+	for panicked(name, fn) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(restartDelay):
+		}
+		slog.Info("restarting after panic", "goroutine", name)
+	}
+}
+
+func panicked(name string, fn func()) (p bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			p = true
+			slog.Error("panic recovered", "goroutine", name, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	fn()
+	return false
+}
+
+// recovered calls fn and returns its error, or the panic of fn as an error, for the goroutines of the notifiers
+// that supervise does not cover, so that a panicking notifier fails alone instead of killing the process.
+func recovered(name string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			slog.Error("panic recovered", "goroutine", name, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	return fn()
+}
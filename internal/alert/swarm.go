@@ -0,0 +1,73 @@
+package alert
+
+import "time"
+
+// SwarmConfig detects the swarms, Count events within RadiusKm of each other during Window,
+// alerted even when each of them is too small to be notified.
+type SwarmConfig struct {
+	// Count is the number of events making a swarm, 0 disables the detection.
+	Count    int           `yaml:"count"`
+	RadiusKm float64       `yaml:"radius_km"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// swarmDetector keeps the events of the last window to detect the swarms.
+type swarmDetector struct {
+	events map[int]swarmEntry
+	// fired are the events that completed a swarm, the swarm is not alerted again around them within the window.
+	fired []swarmEntry
+}
+
+type swarmEntry struct {
+	lat, lon float64
+	// startAt is the unix milli time the event started at.
+	startAt int64
+}
+
+func newSwarmDetector() *swarmDetector {
+	return &swarmDetector{events: make(map[int]swarmEntry)}
+}
+
+// add records the event and returns the number of events of the swarm it completes, 0 when it does not
+// complete one or the swarm has been alerted during the window. An update of an event replaces it.
+func (d *swarmDetector) add(event Event, cfg SwarmConfig) int {
+	if cfg.Count <= 0 {
+		return 0
+	}
+	entry := swarmEntry{lat: event.Latitude, lon: event.Longitude, startAt: event.StartAt}
+	since := event.StartAt - cfg.Window.Milliseconds()
+	for id, e := range d.events {
+		if e.startAt < since {
+			delete(d.events, id)
+		}
+	}
+	fired := d.fired[:0]
+	for _, e := range d.fired {
+		if e.startAt >= since {
+			fired = append(fired, e)
+		}
+	}
+	d.fired = fired
+	d.events[event.EventId] = entry
+
+	count := 0
+	for _, e := range d.events {
+		if entry.near(e, cfg.RadiusKm) {
+			count++
+		}
+	}
+	if count < cfg.Count {
+		return 0
+	}
+	for _, e := range d.fired {
+		if entry.near(e, cfg.RadiusKm) {
+			return 0
+		}
+	}
+	d.fired = append(d.fired, entry)
+	return count
+}
+
+func (e swarmEntry) near(other swarmEntry, radiusKm float64) bool {
+	return distanceKm(e.lat, e.lon, other.lat, other.lon) <= radiusKm
+}
@@ -0,0 +1,49 @@
+package alert
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwarmDetectorAdd(t *testing.T) {
+	cfg := SwarmConfig{Count: 3, RadiusKm: 50, Window: time.Hour}
+	minute := time.Minute.Milliseconds()
+	// near is about 14 km from the epicenter of at, far is several hundred km away
+	at := func(id int, minutes int64) Event {
+		return Event{EventId: id, Latitude: 30, Longitude: 103, StartAt: minutes * minute}
+	}
+	near := func(id int, minutes int64) Event {
+		return Event{EventId: id, Latitude: 30.1, Longitude: 103.1, StartAt: minutes * minute}
+	}
+	far := func(id int, minutes int64) Event {
+		return Event{EventId: id, Latitude: 35, Longitude: 110, StartAt: minutes * minute}
+	}
+	tests := []struct {
+		name   string
+		cfg    SwarmConfig
+		events []Event
+		// want is the result of add for each event
+		want []int
+	}{
+		{"disabled", SwarmConfig{}, []Event{at(1, 0), at(2, 1), at(3, 2)}, []int{0, 0, 0}},
+		{"completes a swarm", cfg, []Event{at(1, 0), near(2, 1), at(3, 2)}, []int{0, 0, 3}},
+		{"is not alerted again within the window", cfg, []Event{at(1, 0), at(2, 1), at(3, 2), near(4, 3)}, []int{0, 0, 3, 0}},
+		{"far events do not count", cfg, []Event{at(1, 0), far(2, 1), at(3, 2), far(4, 3)}, []int{0, 0, 0, 0}},
+		{"the events out of the window expire", cfg, []Event{at(1, 0), at(2, 1), at(3, 70)}, []int{0, 0, 0}},
+		{"an update replaces its event", cfg, []Event{at(1, 0), at(1, 1), at(1, 2), at(2, 3)}, []int{0, 0, 0, 0}},
+		{"a far swarm is alerted of its own", cfg,
+			[]Event{at(1, 0), at(2, 1), at(3, 2), far(4, 3), far(5, 4), far(6, 5)}, []int{0, 0, 3, 0, 0, 3}},
+		{"is alerted again once the window has passed", cfg,
+			[]Event{at(1, 0), at(2, 1), at(3, 2), at(4, 62), at(5, 63), at(6, 64)}, []int{0, 0, 3, 0, 0, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newSwarmDetector()
+			for i, event := range tt.events {
+				if got := d.add(event, tt.cfg); got != tt.want[i] {
+					t.Errorf("add of event %d (#%d) = %d, want %d", i, event.EventId, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
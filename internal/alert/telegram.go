@@ -0,0 +1,51 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// TelegramNotifier sends events to a chat through a Telegram bot.
+type TelegramNotifier struct {
+	Token     string
+	ChatID    string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+type telegramResponse struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+}
+
+func (t *TelegramNotifier) String() string {
+	return "telegram"
+}
+
+func (t *TelegramNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := t.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.Token)
+	status, data, err := postJSON(ctx, t.Client, url, nil, map[string]string{
+		"chat_id":    t.ChatID,
+		"text":       fmt.Sprintf("*%s*\n%s", escapeMarkdown(title), escapeMarkdown(body)),
+		"parse_mode": "Markdown",
+	})
+	if err != nil {
+		return err
+	}
+	var resp telegramResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("telegram: decode response with status %d: %w", status, err)
+	}
+	if !resp.OK {
+		return rejected(status, fmt.Errorf("telegram: %s", resp.Description))
+	}
+	slog.Info("notification successfully", "notifier", "telegram")
+	return nil
+}
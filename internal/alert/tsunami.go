@@ -0,0 +1,58 @@
+package alert
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultOffshoreKeywords tell the offshore epicenters apart in the names of the China EEW and USGS feeds,
+// like 台湾花莲县海域, 日本本州东岸近海, off the east coast of Honshu, Japan or Banda Sea. The bare names of
+// the seas are left out, they are also the names of inland places like 东海县 or 南海区.
+var defaultOffshoreKeywords = []string{
+	"海域", "近海", "海峡", "海沟",
+	"sea", "offshore", "off the", "ocean", "strait", "trench",
+}
+
+// tsunamiRisk reports whether the event is strong enough, at least magnitude, and offshore, its epicenter
+// containing one of the keywords, see matchesKeyword, to possibly cause a tsunami. A magnitude of 0 disables it.
+func tsunamiRisk(event Event, magnitude float64, keywords []string) bool {
+	if magnitude <= 0 || event.Magnitude < magnitude {
+		return false
+	}
+	epicenter := strings.ToLower(event.Epicenter)
+	for _, keyword := range keywords {
+		if matchesKeyword(epicenter, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesKeyword reports whether keyword is a whole word of name: no letter or digit may follow it, nor
+// precede it unless it starts with a Chinese character, as the Chinese names join their words together.
+// So 近海 ends 日本本州东岸近海 but not 近海市, and sea is a word of Banda Sea but not of Seattle.
+func matchesKeyword(name, keyword string) bool {
+	if keyword == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(keyword)
+	for i := 0; i < len(name); {
+		j := strings.Index(name[i:], keyword)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(keyword)
+		before, _ := utf8.DecodeLastRuneInString(name[:start])
+		after, _ := utf8.DecodeRuneInString(name[end:])
+		if (start == 0 || unicode.Is(unicode.Han, first) || !isWordRune(before)) && (end == len(name) || !isWordRune(after)) {
+			return true
+		}
+		i = start + utf8.RuneLen(first)
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,45 @@
+package alert
+
+import "testing"
+
+func TestTsunamiRisk(t *testing.T) {
+	tests := []struct {
+		name      string
+		epicenter string
+		magnitude float64
+		want      bool
+	}{
+		{"china sea area", "台湾花莲县海域", 7, true},
+		{"china near sea", "日本本州东岸近海", 7, true},
+		{"china strait", "台湾海峡", 7, true},
+		{"china trench", "琉球海沟", 7, true},
+		{"usgs off the coast", "off the east coast of Honshu, Japan", 7, true},
+		{"usgs sea", "Banda Sea", 7, true},
+		{"usgs sea of", "Sea of Okhotsk", 7, true},
+		{"usgs offshore", "offshore Chiapas, Mexico", 7, true},
+		{"usgs ocean", "south Indian Ocean", 7, true},
+		{"usgs trench", "Kuril-Kamchatka Trench", 7, true},
+		{"inland county named after a sea", "江苏连云港市东海县", 7, false},
+		{"inland district named after a sea", "广东佛山市南海区", 7, false},
+		{"inland city starting with a keyword", "近海市", 7, false},
+		{"inland city containing sea", "10 km N of Seattle, Washington", 7, false},
+		{"onshore near the coast", "near the coast of Ecuador", 7, false},
+		{"inland", "四川成都市青白江区", 7, false},
+		{"offshore at the threshold", "台湾花莲县海域", 6.5, true},
+		{"offshore below the threshold", "台湾花莲县海域", 6.4, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{Epicenter: tt.epicenter, Magnitude: tt.magnitude}
+			if got := tsunamiRisk(event, 6.5, defaultOffshoreKeywords); got != tt.want {
+				t.Errorf("tsunamiRisk(%q, M%.1f) = %t, want %t", tt.epicenter, tt.magnitude, got, tt.want)
+			}
+		})
+	}
+	if tsunamiRisk(Event{Epicenter: "Banda Sea", Magnitude: 9}, 0, defaultOffshoreKeywords) {
+		t.Error("tsunamiRisk with a magnitude of 0 is not disabled")
+	}
+	if !tsunamiRisk(Event{Epicenter: "Mid-Atlantic Ridge", Magnitude: 7}, 6.5, []string{"Ridge"}) {
+		t.Error("tsunamiRisk does not match a configured keyword case-insensitively")
+	}
+}
@@ -0,0 +1,85 @@
+package alert
+
+import (
+	"context"
+	"hash/fnv"
+	"net/http"
+	"time"
+)
+
+const usgsURL = "https://earthquake.usgs.gov/earthquakes/feed/v1.0/summary/all_hour.geojson"
+
+// USGSSource polls a GeoJSON summary feed of the U.S. Geological Survey.
+type USGSSource struct {
+	URL            string
+	Client         *http.Client
+	MaxRetries     int
+	RetryBaseDelay time.Duration
+
+	validators validators
+}
+
+type usgsFeed struct {
+	Features []usgsFeature `json:"features"`
+}
+
+type usgsFeature struct {
+	ID         string `json:"id"`
+	Properties struct {
+		Mag     float64 `json:"mag"`
+		Place   string  `json:"place"`
+		Time    int64   `json:"time"`
+		Updated int64   `json:"updated"`
+		Nst     int     `json:"nst"`
+	} `json:"properties"`
+	Geometry struct {
+		// Coordinates are longitude, latitude and depth in kilometers.
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// Poll returns every event of the feed regardless of since, as USGS publishes them minutes late and out of order.
+func (s *USGSSource) Poll(ctx context.Context, _ int64) ([]Event, error) {
+	feed, err := query[usgsFeed](ctx, s.Client, s.URL, &s.validators, s.MaxRetries, s.RetryBaseDelay)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	for _, f := range feed.Features {
+		if len(f.Geometry.Coordinates) < 3 {
+			continue
+		}
+		events = append(events, f.event())
+	}
+	return events, nil
+}
+
+func (s *USGSSource) late() bool {
+	return true
+}
+
+// probe queries the feed once, leaving the validators of the polls as is.
+func (s *USGSSource) probe(ctx context.Context, _ int64) error {
+	_, err := query[usgsFeed](ctx, s.Client, s.URL, nil, 0, 0)
+	return err
+}
+
+func (f usgsFeature) event() Event {
+	// the string id is hashed into the numeric EventId of the pipeline
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(f.ID))
+	// the revisions of the magnitude and the location are newer updates, counted in seconds to fit an int
+	return Event{
+		EventId:   int(h.Sum32()),
+		Updates:   int(f.Properties.Updated / 1000),
+		Longitude: f.Geometry.Coordinates[0],
+		Latitude:  f.Geometry.Coordinates[1],
+		Depth:     f.Geometry.Coordinates[2],
+		Epicenter: f.Properties.Place,
+		StartAt:   f.Properties.Time,
+		UpdateAt:  f.Properties.Updated,
+		Magnitude: f.Properties.Mag,
+		InsideNet: 1,
+		Sations:   f.Properties.Nst,
+	}
+}
@@ -0,0 +1,43 @@
+package alert
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Build metadata set with -ldflags "-X earthquake-alert/internal/alert.Version=...", see the Makefile.
+// The ones left empty are read from the build info embedded by the go command.
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+func init() {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if Version == "" && info.Main.Version != "(devel)" {
+		Version = info.Main.Version
+	}
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "vcs.revision" && Commit == "":
+			Commit = s.Value
+		case s.Key == "vcs.time" && BuildDate == "":
+			BuildDate = s.Value
+		}
+	}
+}
+
+// VersionInfo describes the build for -version.
+func VersionInfo() string {
+	return fmt.Sprintf("earthquake-alert %s (commit %s, built %s)",
+		orDefault(Version, "dev"), orDefault(Commit, "unknown"), orDefault(BuildDate, "unknown"))
+}
+
+// defaultUserAgent identifies the requests of this tool and its version to the upstreams and notifiers.
+func defaultUserAgent() string {
+	return "earthquake-alert/" + orDefault(Version, "dev")
+}
@@ -0,0 +1,45 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// WebhookNotifier posts the raw event as JSON to an arbitrary HTTP endpoint.
+type WebhookNotifier struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+}
+
+// parseHeaders converts "Name: value" pairs into an http.Header.
+func parseHeaders(values []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", v)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return header, nil
+}
+
+func (w *WebhookNotifier) String() string {
+	return "webhook"
+}
+
+func (w *WebhookNotifier) Send(ctx context.Context, event Event) error {
+	status, data, err := postJSON(ctx, w.Client, w.URL, w.Header, event)
+	if err != nil {
+		return err
+	}
+	if status < 200 || status > 299 {
+		return fmt.Errorf("webhook: %w", newStatusError(status, data))
+	}
+	slog.Info("notification successfully", "notifier", "webhook")
+	return nil
+}
@@ -0,0 +1,52 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// weComTooFast is the errcode of a robot sending more than 20 messages a minute.
+const weComTooFast = 45009
+
+// WeComNotifier posts events as markdown to the webhook of a WeCom (企业微信) group robot.
+type WeComNotifier struct {
+	URL       string
+	Client    *http.Client
+	Formatter *Formatter
+}
+
+func (w *WeComNotifier) String() string {
+	return "wecom"
+}
+
+func (w *WeComNotifier) Send(ctx context.Context, event Event) error {
+	title, body, err := w.Formatter.format(event)
+	if err != nil {
+		return err
+	}
+	status, data, err := postJSON(ctx, w.Client, w.URL, nil, map[string]any{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"content": fmt.Sprintf("**<font color=\"warning\">%s</font>**\n%s", title, body),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var resp robotResponse
+	if err = json.Unmarshal(data, &resp); err != nil {
+		return fmt.Errorf("wecom: decode response with status %d: %w", status, err)
+	}
+	if resp.ErrCode != 0 {
+		err := fmt.Errorf("wecom: errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+		if resp.ErrCode == weComTooFast {
+			return err
+		}
+		return rejected(status, err)
+	}
+	slog.Info("notification successfully", "notifier", "wecom")
+	return nil
+}